@@ -0,0 +1,142 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/alitto/pond"
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+)
+
+const (
+	CronEnabled   = "enabled"
+	CronDisabled  = "disabled"
+	CronSuspended = "suspended"
+)
+
+// Cron is a script the hosting runs at the given frequency.
+type Cron struct {
+	ID int64 `json:"id,omitempty"`
+	// Command is the path of the script, relative to the home of the
+	// hosting.
+	Command     string `json:"command"`
+	Description string `json:"description"`
+	// Email receives the errors of the runs when set.
+	Email string `json:"email,omitempty"`
+	// Frequency has the format of the first 5 fields of a crontab.
+	Frequency string `json:"frequency"`
+	// Language is the runtime of the script, such as php8.2 or node18.
+	Language string `json:"language"`
+	Status   string `json:"status,omitempty"`
+}
+
+func (client *Client) ListCrons(hosting string) ([]int64, error) {
+	var ids []int64
+	url := fmt.Sprintf("/hosting/web/%s/cron", hosting)
+
+	if err := client.Get(url, &ids); err != nil {
+		return nil, xerrors.Errorf("failed to GET %s: %w", url, err)
+	}
+
+	return ids, nil
+}
+
+// Crons returns the crons of the hosting, sorted by ID.
+func (client *Client) Crons(hosting string) ([]Cron, error) {
+	ids, err := client.ListCrons(hosting)
+	if err != nil {
+		return nil, err
+	}
+
+	var mu sync.Mutex
+	crons := make([]Cron, 0, len(ids))
+
+	pool := pond.New(20, 20)
+	defer pool.StopAndWait()
+
+	group, _ := pool.GroupContext(context.Background())
+
+	for _, id := range ids {
+		id := id
+
+		group.Submit(func() error {
+			cron, err := client.GetCron(hosting, id)
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			crons = append(crons, *cron)
+			mu.Unlock()
+
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(crons, func(a Cron, b Cron) bool {
+		return a.ID < b.ID
+	})
+
+	return crons, nil
+}
+
+func (client *Client) GetCron(hosting string, id int64) (*Cron, error) {
+	var cron Cron
+	url := fmt.Sprintf("/hosting/web/%s/cron/%d", hosting, id)
+
+	if err := client.Get(url, &cron); err != nil {
+		return nil, xerrors.Errorf("failed to GET %s: %w", url, err)
+	}
+
+	return &cron, nil
+}
+
+func (client *Client) CreateCron(hosting string, cron *Cron) error {
+	url := fmt.Sprintf("/hosting/web/%s/cron", hosting)
+
+	payload := *cron
+	payload.ID = 0
+
+	if err := client.Post(url, payload, nil); err != nil {
+		return xerrors.Errorf("failed to POST %s: %w", url, err)
+	}
+
+	return nil
+}
+
+// UpdateCron replaces the settings of the cron cron.ID.
+func (client *Client) UpdateCron(hosting string, cron *Cron) error {
+	url := fmt.Sprintf("/hosting/web/%s/cron/%d", hosting, cron.ID)
+
+	// every field is sent, an empty email included, so that it's cleared
+	payload := struct {
+		Command     string `json:"command"`
+		Description string `json:"description"`
+		Email       string `json:"email"`
+		Frequency   string `json:"frequency"`
+		Language    string `json:"language"`
+		Status      string `json:"status,omitempty"`
+	}{cron.Command, cron.Description, cron.Email, cron.Frequency, cron.Language, cron.Status}
+
+	if err := client.Put(url, payload, nil); err != nil {
+		return xerrors.Errorf("failed to PUT %s: %w", url, err)
+	}
+
+	return nil
+}
+
+func (client *Client) DeleteCron(hosting string, id int64) error {
+	url := fmt.Sprintf("/hosting/web/%s/cron/%d", hosting, id)
+
+	if err := client.Delete(url, nil); err != nil {
+		return xerrors.Errorf("failed to DELETE %s: %w", url, err)
+	}
+
+	return nil
+}
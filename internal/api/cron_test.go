@@ -0,0 +1,54 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ovh/go-ovh/ovh"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateCronClearsEmail(t *testing.T) {
+	t.Parallel()
+
+	var body map[string]any
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth/time":
+			w.Write([]byte("0")) //nolint:errcheck
+		case "/hosting/web/example.cluster/cron/42":
+			require.Equal(t, http.MethodPut, r.Method)
+
+			b, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(b, &body))
+
+			w.Write([]byte("null")) //nolint:errcheck
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	ovhClient, err := ovh.NewClient(server.URL, "ak", "as", "ck")
+	require.NoError(t, err)
+
+	client := &Client{ovhClient}
+
+	err = client.UpdateCron("example.cluster", &Cron{
+		ID:        42,
+		Command:   "www/cron.php",
+		Frequency: "0 3 * * *",
+		Language:  "php8.2",
+		Status:    CronEnabled,
+	})
+	require.NoError(t, err)
+
+	require.Contains(t, body, "email")
+	require.Equal(t, "", body["email"])
+	require.NotContains(t, body, "id")
+}
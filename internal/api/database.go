@@ -0,0 +1,181 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/alitto/pond"
+	"go.mlcdf.fr/owh/internal/unit"
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+)
+
+type Database struct {
+	Name      string         `json:"name"`
+	User      string         `json:"user"`
+	Type      string         `json:"type"`
+	Version   string         `json:"version"`
+	Server    string         `json:"server"`
+	Port      int            `json:"port"`
+	State     string         `json:"state"`
+	Mode      string         `json:"mode"`
+	GUIURL    string         `json:"guiURL"`
+	QuotaSize unit.UnitValue `json:"quotaSize"`
+	QuotaUsed unit.UnitValue `json:"quotaUsed"`
+}
+
+// DatabaseCapability is an offer databases can be created with.
+type DatabaseCapability struct {
+	Type      string         `json:"type"`
+	Available int            `json:"available"`
+	Quota     unit.UnitValue `json:"quota"`
+}
+
+// DatabaseCreation is the payload of a database creation.
+type DatabaseCreation struct {
+	Capability string `json:"capabilitie"`
+	Password   string `json:"password,omitempty"`
+	Quota      int    `json:"quota"`
+	Type       string `json:"type"`
+	User       string `json:"user"`
+	Version    string `json:"version,omitempty"`
+}
+
+func (client *Client) ListDatabases(hosting string) ([]string, error) {
+	var names []string
+	url := fmt.Sprintf("/hosting/web/%s/database", hosting)
+
+	if err := client.Get(url, &names); err != nil {
+		return nil, xerrors.Errorf("failed to GET %s: %w", url, err)
+	}
+
+	slices.Sort(names)
+
+	return names, nil
+}
+
+func (client *Client) Databases(hosting string) ([]Database, error) {
+	names, err := client.ListDatabases(hosting)
+	if err != nil {
+		return nil, err
+	}
+
+	var mu sync.Mutex
+	databases := make([]Database, 0, len(names))
+
+	pool := pond.New(20, 20)
+	defer pool.StopAndWait()
+
+	group, _ := pool.GroupContext(context.Background())
+
+	for _, name := range names {
+		name := name
+
+		group.Submit(func() error {
+			database, err := client.GetDatabase(hosting, name)
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			databases = append(databases, *database)
+			mu.Unlock()
+
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(databases, func(a Database, b Database) bool {
+		return a.Name < b.Name
+	})
+
+	return databases, nil
+}
+
+func (client *Client) GetDatabase(hosting string, name string) (*Database, error) {
+	var database Database
+	url := fmt.Sprintf("/hosting/web/%s/database/%s", hosting, name)
+
+	if err := client.Get(url, &database); err != nil {
+		return nil, xerrors.Errorf("failed to GET %s: %w", url, err)
+	}
+
+	return &database, nil
+}
+
+// DatabaseCapabilities lists the offers databases can be created with on the
+// hosting.
+func (client *Client) DatabaseCapabilities(hosting string) ([]DatabaseCapability, error) {
+	var capabilities []DatabaseCapability
+	url := fmt.Sprintf("/hosting/web/%s/databaseCreationCapabilities", hosting)
+
+	if err := client.Get(url, &capabilities); err != nil {
+		return nil, xerrors.Errorf("failed to GET %s: %w", url, err)
+	}
+
+	return capabilities, nil
+}
+
+// DatabaseVersions lists the versions available for the databases of type
+// databaseType.
+func (client *Client) DatabaseVersions(hosting string, databaseType string) ([]string, error) {
+	var response struct {
+		Default string   `json:"default"`
+		List    []string `json:"list"`
+	}
+	url := fmt.Sprintf("/hosting/web/%s/databaseAvailableVersion?type=%s", hosting, databaseType)
+
+	if err := client.Get(url, &response); err != nil {
+		return nil, xerrors.Errorf("failed to GET %s: %w", url, err)
+	}
+
+	// the default version first
+	if i := slices.Index(response.List, response.Default); i > 0 {
+		response.List = slices.Delete(response.List, i, i+1)
+		response.List = slices.Insert(response.List, 0, response.Default)
+	}
+
+	return response.List, nil
+}
+
+func (client *Client) CreateDatabase(hosting string, payload *DatabaseCreation) (int64, error) {
+	var task Task
+	url := fmt.Sprintf("/hosting/web/%s/database", hosting)
+
+	if err := client.Post(url, payload, &task); err != nil {
+		return 0, xerrors.Errorf("failed to POST %s: %w", url, err)
+	}
+
+	return task.ID, nil
+}
+
+func (client *Client) DeleteDatabase(hosting string, name string) (int64, error) {
+	var task Task
+	url := fmt.Sprintf("/hosting/web/%s/database/%s", hosting, name)
+
+	if err := client.Delete(url, &task); err != nil {
+		return 0, xerrors.Errorf("failed to DELETE %s: %w", url, err)
+	}
+
+	return task.ID, nil
+}
+
+func (client *Client) ChangeDatabasePassword(hosting string, name string, password string) (int64, error) {
+	var task Task
+	url := fmt.Sprintf("/hosting/web/%s/database/%s/changePassword", hosting, name)
+
+	payload := struct {
+		Password string `json:"password"`
+	}{password}
+
+	if err := client.Post(url, payload, &task); err != nil {
+		return 0, xerrors.Errorf("failed to POST %s: %w", url, err)
+	}
+
+	return task.ID, nil
+}
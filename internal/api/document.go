@@ -0,0 +1,67 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"golang.org/x/xerrors"
+)
+
+// Document is a file stored on the account, used to hand files to other
+// APIs such as the database imports.
+type Document struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	PutURL string `json:"putUrl"`
+	GetURL string `json:"getUrl"`
+}
+
+// CreateDocument creates an empty document, its content is then sent with
+// UploadDocument.
+func (client *Client) CreateDocument(name string) (*Document, error) {
+	var document Document
+
+	payload := struct {
+		Name string `json:"name"`
+	}{name}
+
+	if err := client.Post("/me/document", payload, &document); err != nil {
+		return nil, xerrors.Errorf("failed to POST /me/document: %w", err)
+	}
+
+	return &document, nil
+}
+
+// UploadDocument sends size bytes of r as the content of the document.
+func (client *Client) UploadDocument(document *Document, r io.Reader, size int64) error {
+	req, err := http.NewRequest(http.MethodPut, document.PutURL, r)
+	if err != nil {
+		return err
+	}
+
+	req.ContentLength = size
+
+	res, err := client.Client.Client.Do(req)
+	if err != nil {
+		return xerrors.Errorf("failed to upload document %s: %w", document.Name, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return xerrors.Errorf("failed to upload document %s: %s", document.Name, res.Status)
+	}
+
+	return nil
+}
+
+func (client *Client) DeleteDocument(id string) error {
+	url := fmt.Sprintf("/me/document/%s", id)
+
+	if err := client.Delete(url, nil); err != nil {
+		return xerrors.Errorf("failed to DELETE %s: %w", url, err)
+	}
+
+	return nil
+}
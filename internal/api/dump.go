@@ -0,0 +1,139 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/alitto/pond"
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+)
+
+// DatabaseDump is a backup of a database, downloadable from URL until its
+// DeletionDate.
+type DatabaseDump struct {
+	ID           int64     `json:"id"`
+	DatabaseName string    `json:"databaseName"`
+	Type         string    `json:"type"`
+	Status       string    `json:"status"`
+	URL          string    `json:"url"`
+	CreationDate time.Time `json:"creationDate"`
+	DeletionDate time.Time `json:"deletionDate"`
+	SnapshotDate time.Time `json:"snapshotDate"`
+}
+
+func (client *Client) ListDatabaseDumps(hosting string, name string) ([]int64, error) {
+	var ids []int64
+	url := fmt.Sprintf("/hosting/web/%s/database/%s/dump", hosting, name)
+
+	if err := client.Get(url, &ids); err != nil {
+		return nil, xerrors.Errorf("failed to GET %s: %w", url, err)
+	}
+
+	return ids, nil
+}
+
+// DatabaseDumps returns the dumps of the database, the most recent first.
+func (client *Client) DatabaseDumps(hosting string, name string) ([]DatabaseDump, error) {
+	ids, err := client.ListDatabaseDumps(hosting, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var mu sync.Mutex
+	dumps := make([]DatabaseDump, 0, len(ids))
+
+	pool := pond.New(20, 20)
+	defer pool.StopAndWait()
+
+	group, _ := pool.GroupContext(context.Background())
+
+	for _, id := range ids {
+		id := id
+
+		group.Submit(func() error {
+			dump, err := client.GetDatabaseDump(hosting, name, id)
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			dumps = append(dumps, *dump)
+			mu.Unlock()
+
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(dumps, func(a DatabaseDump, b DatabaseDump) bool {
+		return a.CreationDate.After(b.CreationDate)
+	})
+
+	return dumps, nil
+}
+
+func (client *Client) GetDatabaseDump(hosting string, name string, id int64) (*DatabaseDump, error) {
+	var dump DatabaseDump
+	url := fmt.Sprintf("/hosting/web/%s/database/%s/dump/%d", hosting, name, id)
+
+	if err := client.Get(url, &dump); err != nil {
+		return nil, xerrors.Errorf("failed to GET %s: %w", url, err)
+	}
+
+	return &dump, nil
+}
+
+// DumpDatabase dumps the database as it is at date: "now", or the backup of
+// the previous day or week with "daily.1" and "weekly.1".
+func (client *Client) DumpDatabase(hosting string, name string, date string) (int64, error) {
+	var task Task
+	url := fmt.Sprintf("/hosting/web/%s/database/%s/dump", hosting, name)
+
+	payload := struct {
+		Date      string `json:"date"`
+		SendEmail bool   `json:"sendEmail"`
+	}{date, false}
+
+	if err := client.Post(url, payload, &task); err != nil {
+		return 0, xerrors.Errorf("failed to POST %s: %w", url, err)
+	}
+
+	return task.ID, nil
+}
+
+// RestoreDatabaseDump replaces the content of the database with the dump.
+func (client *Client) RestoreDatabaseDump(hosting string, name string, id int64) (int64, error) {
+	var task Task
+	url := fmt.Sprintf("/hosting/web/%s/database/%s/dump/%d/restore", hosting, name, id)
+
+	if err := client.Post(url, nil, &task); err != nil {
+		return 0, xerrors.Errorf("failed to POST %s: %w", url, err)
+	}
+
+	return task.ID, nil
+}
+
+// ImportDatabase runs the SQL of the document in the database, after
+// dropping its tables when flush is true.
+func (client *Client) ImportDatabase(hosting string, name string, documentID string, flush bool) (int64, error) {
+	var task Task
+	url := fmt.Sprintf("/hosting/web/%s/database/%s/import", hosting, name)
+
+	payload := struct {
+		DocumentID    string `json:"documentId"`
+		FlushDatabase bool   `json:"flushDatabase"`
+		SendEmail     bool   `json:"sendEmail"`
+	}{documentID, flush, false}
+
+	if err := client.Post(url, payload, &task); err != nil {
+		return 0, xerrors.Errorf("failed to POST %s: %w", url, err)
+	}
+
+	return task.ID, nil
+}
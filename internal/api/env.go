@@ -0,0 +1,140 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/alitto/pond"
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+)
+
+const (
+	EnvVarString   = "string"
+	EnvVarInteger  = "integer"
+	EnvVarPassword = "password"
+)
+
+// EnvVar is an environment variable of the websites of the hosting. The
+// variables of type EnvVarPassword are secrets.
+type EnvVar struct {
+	Key    string `json:"key"`
+	Type   string `json:"type"`
+	Value  string `json:"value"`
+	Status string `json:"status,omitempty"`
+}
+
+// IsSecret reports whether the value of the variable must not be shown.
+func (v *EnvVar) IsSecret() bool {
+	return v.Type == EnvVarPassword
+}
+
+func (client *Client) ListEnvVars(hosting string) ([]string, error) {
+	var keys []string
+	url := fmt.Sprintf("/hosting/web/%s/envVar", hosting)
+
+	if err := client.Get(url, &keys); err != nil {
+		return nil, xerrors.Errorf("failed to GET %s: %w", url, err)
+	}
+
+	return keys, nil
+}
+
+// EnvVars returns the environment variables of the hosting, sorted by key.
+func (client *Client) EnvVars(hosting string) ([]EnvVar, error) {
+	keys, err := client.ListEnvVars(hosting)
+	if err != nil {
+		return nil, err
+	}
+
+	var mu sync.Mutex
+	vars := make([]EnvVar, 0, len(keys))
+
+	pool := pond.New(20, 20)
+	defer pool.StopAndWait()
+
+	group, _ := pool.GroupContext(context.Background())
+
+	for _, key := range keys {
+		key := key
+
+		group.Submit(func() error {
+			v, err := client.GetEnvVar(hosting, key)
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			vars = append(vars, *v)
+			mu.Unlock()
+
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(vars, func(a EnvVar, b EnvVar) bool {
+		return a.Key < b.Key
+	})
+
+	return vars, nil
+}
+
+func (client *Client) GetEnvVar(hosting string, key string) (*EnvVar, error) {
+	var v EnvVar
+	url := fmt.Sprintf("/hosting/web/%s/envVar/%s", hosting, key)
+
+	if err := client.Get(url, &v); err != nil {
+		return nil, xerrors.Errorf("failed to GET %s: %w", url, err)
+	}
+
+	return &v, nil
+}
+
+func (client *Client) CreateEnvVar(hosting string, v *EnvVar) (int64, error) {
+	var task Task
+	url := fmt.Sprintf("/hosting/web/%s/envVar", hosting)
+
+	payload := struct {
+		Key   string `json:"key"`
+		Type  string `json:"type"`
+		Value string `json:"value"`
+	}{v.Key, v.Type, v.Value}
+
+	if err := client.Post(url, payload, &task); err != nil {
+		return 0, xerrors.Errorf("failed to POST %s: %w", url, err)
+	}
+
+	return task.ID, nil
+}
+
+// UpdateEnvVar changes the type and the value of the variable v.Key.
+func (client *Client) UpdateEnvVar(hosting string, v *EnvVar) error {
+	url := fmt.Sprintf("/hosting/web/%s/envVar/%s", hosting, v.Key)
+
+	payload := struct {
+		Type  string `json:"type"`
+		Value string `json:"value"`
+	}{v.Type, v.Value}
+
+	if err := client.Put(url, payload, nil); err != nil {
+		return xerrors.Errorf("failed to PUT %s: %w", url, err)
+	}
+
+	return nil
+}
+
+func (client *Client) DeleteEnvVar(hosting string, key string) (int64, error) {
+	var task Task
+	url := fmt.Sprintf("/hosting/web/%s/envVar/%s", hosting, key)
+
+	if err := client.Delete(url, &task); err != nil {
+		return 0, xerrors.Errorf("failed to DELETE %s: %w", url, err)
+	}
+
+	return task.ID, nil
+}
@@ -46,6 +46,8 @@ type AttachedDomain struct {
 	Firewall string `json:"firewall"`
 	Path     string `json:"path"`
 	SSL      bool   `json:"ssl"`
+	// RuntimeID is the runtime of the domain, on the offers having several.
+	RuntimeID int64 `json:"runtimeId,omitempty"`
 }
 
 func (client *Client) GetHosting(hosting string) (*HostingInfo, error) {
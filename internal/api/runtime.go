@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/alitto/pond"
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+)
+
+// OvhConfig is the runtime set by the .ovhconfig file of a path of the
+// hosting.
+type OvhConfig struct {
+	ID            int64  `json:"id"`
+	Path          string `json:"path"`
+	Container     string `json:"container"`
+	EngineName    string `json:"engineName"`
+	EngineVersion string `json:"engineVersion"`
+	Environment   string `json:"environment"`
+	HTTPFirewall  string `json:"httpFirewall"`
+	Status        string `json:"status"`
+}
+
+// OvhConfigCapability is a runtime the hosting supports.
+type OvhConfigCapability struct {
+	Container     string `json:"container"`
+	Default       bool   `json:"default"`
+	EngineName    string `json:"engineName"`
+	EngineVersion string `json:"engineVersion"`
+	SupportStatus string `json:"supportStatus"`
+}
+
+// OvhConfigChange is the payload of a change of the runtime of a path.
+type OvhConfigChange struct {
+	Container     string `json:"container,omitempty"`
+	EngineName    string `json:"engineName,omitempty"`
+	EngineVersion string `json:"engineVersion,omitempty"`
+	Environment   string `json:"environment,omitempty"`
+	HTTPFirewall  string `json:"httpFirewall,omitempty"`
+}
+
+// Runtime is a runtime of the offers running several ones, such as the
+// Cloud Web offers.
+type Runtime struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	PublicDir string `json:"publicDir"`
+	AppEnv    string `json:"appEnv"`
+	IsDefault bool   `json:"isDefault"`
+	Status    string `json:"status"`
+}
+
+func (client *Client) ListOvhConfigs(hosting string) ([]int64, error) {
+	var ids []int64
+	url := fmt.Sprintf("/hosting/web/%s/ovhConfig?historical=false", hosting)
+
+	if err := client.Get(url, &ids); err != nil {
+		return nil, xerrors.Errorf("failed to GET %s: %w", url, err)
+	}
+
+	return ids, nil
+}
+
+// OvhConfigs returns the current runtimes of the paths of the hosting,
+// sorted by path.
+func (client *Client) OvhConfigs(hosting string) ([]OvhConfig, error) {
+	ids, err := client.ListOvhConfigs(hosting)
+	if err != nil {
+		return nil, err
+	}
+
+	var mu sync.Mutex
+	configs := make([]OvhConfig, 0, len(ids))
+
+	pool := pond.New(20, 20)
+	defer pool.StopAndWait()
+
+	group, _ := pool.GroupContext(context.Background())
+
+	for _, id := range ids {
+		id := id
+
+		group.Submit(func() error {
+			var config OvhConfig
+			url := fmt.Sprintf("/hosting/web/%s/ovhConfig/%d", hosting, id)
+
+			if err := client.Get(url, &config); err != nil {
+				return xerrors.Errorf("failed to GET %s: %w", url, err)
+			}
+
+			mu.Lock()
+			configs = append(configs, config)
+			mu.Unlock()
+
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(configs, func(a OvhConfig, b OvhConfig) bool {
+		return a.Path < b.Path
+	})
+
+	return configs, nil
+}
+
+// ChangeOvhConfig changes the runtime of the path of the OvhConfig id, by
+// rewriting its .ovhconfig file.
+func (client *Client) ChangeOvhConfig(hosting string, id int64, change *OvhConfigChange) (int64, error) {
+	var task Task
+	url := fmt.Sprintf("/hosting/web/%s/ovhConfig/%d/changeConfiguration", hosting, id)
+
+	if err := client.Post(url, change, &task); err != nil {
+		return 0, xerrors.Errorf("failed to POST %s: %w", url, err)
+	}
+
+	return task.ID, nil
+}
+
+func (client *Client) OvhConfigCapabilities(hosting string) ([]OvhConfigCapability, error) {
+	var capabilities []OvhConfigCapability
+	url := fmt.Sprintf("/hosting/web/%s/ovhConfigCapabilities", hosting)
+
+	if err := client.Get(url, &capabilities); err != nil {
+		return nil, xerrors.Errorf("failed to GET %s: %w", url, err)
+	}
+
+	return capabilities, nil
+}
+
+func (client *Client) GetRuntime(hosting string, id int64) (*Runtime, error) {
+	var runtime Runtime
+	url := fmt.Sprintf("/hosting/web/%s/runtime/%d", hosting, id)
+
+	if err := client.Get(url, &runtime); err != nil {
+		return nil, xerrors.Errorf("failed to GET %s: %w", url, err)
+	}
+
+	return &runtime, nil
+}
@@ -1,6 +1,8 @@
 package cmdutil
 
 import (
+	"strings"
+
 	"github.com/charmbracelet/lipgloss"
 	"golang.org/x/xerrors"
 )
@@ -34,3 +36,15 @@ func Special(str string) string {
 func Bold(str string) string {
 	return lipgloss.NewStyle().Bold(true).Render(str)
 }
+
+// StringSlice is a flag.Value collecting the values of a repeated flag.
+type StringSlice []string
+
+func (s *StringSlice) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *StringSlice) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
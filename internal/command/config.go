@@ -0,0 +1,28 @@
+package command
+
+import (
+	"strings"
+
+	"github.com/mitchellh/cli"
+)
+
+type ConfigCommand struct {
+	App
+}
+
+func (c *ConfigCommand) Help() string {
+	helpText := `
+Usage: owh config [--help] <command> [<args>]
+	
+  Manages the configuration of owh.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ConfigCommand) Synopsis() string {
+	return "Manage the configuration"
+}
+
+func (c *ConfigCommand) Run(args []string) int {
+	return cli.RunResultHelp
+}
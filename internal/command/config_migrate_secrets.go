@@ -0,0 +1,68 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.mlcdf.fr/owh/internal/cmdutil"
+	"go.mlcdf.fr/owh/internal/config"
+)
+
+type ConfigMigrateSecretsCommand struct {
+	App
+}
+
+func (c *ConfigMigrateSecretsCommand) Help() string {
+	helpText := `
+Usage: owh config migrate-secrets [options]
+
+  Moves the consumer key and the ssh passwords out of the config file, to a
+  secret store.
+
+Options:
+  --store   Secret store to use, one of:
+              keyring     Secret Service keyring (GNOME Keyring, KWallet...),
+                          through secret-tool
+              pass        The standard unix password manager
+              file        A file encrypted with the passphrase of the
+                          OWH_SECRETS_PASSPHRASE environment variable
+              plaintext   The config file
+            (default: keyring if secret-tool is installed, else pass if
+            it's installed, else file)
+
+  The store can also be chosen with the OWH_SECRET_STORE environment
+  variable. The secrets are read from the store they were last moved to
+  until this command is run.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ConfigMigrateSecretsCommand) Synopsis() string {
+	return "Move secrets to the keyring, pass or an encrypted file"
+}
+
+func (c *ConfigMigrateSecretsCommand) Run(args []string) int {
+	var store string
+
+	flags := flag.NewFlagSet("migrate-secrets", flag.ExitOnError)
+
+	defaultStore := os.Getenv(config.ENV_SECRET_STORE)
+	if defaultStore == "" {
+		defaultStore = config.DefaultSecretStore()
+	}
+
+	flags.StringVar(&store, "store", defaultStore, "")
+
+	if err := flags.Parse(args); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if err := c.Config.MigrateSecrets(store); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	fmt.Printf("Secrets are stored in %s\n", cmdutil.Highlight(store))
+	return 0
+}
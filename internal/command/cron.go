@@ -0,0 +1,125 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/mitchellh/cli"
+	"go.mlcdf.fr/owh/internal/api"
+)
+
+type CronCommand struct {
+	App
+}
+
+func (c *CronCommand) Help() string {
+	helpText := `
+Usage: owh cron [--help] <command> [<args>]
+
+  Manages the scripts the hosting runs on a schedule.
+
+  When .owh.json lists "crons", 'owh deploy' creates and updates the crons of
+  the hosting to match them, undoing the changes made with these commands.
+  The crons belong to the whole hosting: a deployment only removes the ones
+  whose command is in the directory of the website, such as
+  example.com/cron.php, and keeps those of other websites. A cron of
+  .owh.json looks like:
+
+    {"command": "www/cron.php", "frequency": "0 3 * * *", "language": "php8.2",
+     "email": "ops@example.com", "description": "nightly", "disabled": false}
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *CronCommand) Synopsis() string {
+	return "Manage crons"
+}
+
+func (c *CronCommand) Run(args []string) int {
+	return cli.RunResultHelp
+}
+
+// selectCron returns the cron whose ID is arg, or asks for it when arg is
+// empty.
+func selectCron(client *api.Client, hosting string, arg string, isInteractive bool) (*api.Cron, error) {
+	if arg != "" {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron ID %s", arg)
+		}
+
+		return client.GetCron(hosting, id)
+	}
+
+	if !isInteractive {
+		return nil, fmt.Errorf("missing positional argument ID")
+	}
+
+	crons, err := client.Crons(hosting)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(crons) == 0 {
+		return nil, fmt.Errorf("no cron found on hosting %s", hosting)
+	}
+
+	options := make([]string, 0, len(crons))
+	for _, cron := range crons {
+		options = append(options, fmt.Sprintf("%d %s (%s)", cron.ID, cron.Command, cron.Frequency))
+	}
+
+	var i int
+	prompt := &survey.Select{Message: "Cron", Options: options}
+
+	if err := survey.AskOne(prompt, &i); err != nil {
+		return nil, err
+	}
+
+	return &crons[i], nil
+}
+
+// setCronStatus runs the command name, which sets the status of a cron.
+func setCronStatus(c *App, args []string, name string, status string) int {
+	var hosting string
+
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+
+	flags.StringVar(&hosting, "hosting", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if hosting == "" {
+		link, err := c.EnsureLink()
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		hosting = link.Hosting
+	}
+
+	client, err := c.LoggedClient()
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	cron, err := selectCron(client, hosting, flags.Arg(0), c.IsInteractive)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	cron.Status = status
+
+	if err := client.UpdateCron(hosting, cron); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	fmt.Printf("Cron %d %s\n", cron.ID, status)
+
+	return 0
+}
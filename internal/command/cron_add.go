@@ -0,0 +1,102 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"go.mlcdf.fr/owh/internal/api"
+	"go.mlcdf.fr/owh/internal/cmdutil"
+	"go.mlcdf.fr/owh/internal/flow"
+)
+
+type CronAddCommand struct {
+	App
+}
+
+func (c *CronAddCommand) Help() string {
+	helpText := `
+Usage: owh cron add [<options>]
+
+  Creates a cron.
+
+Options:
+  --hosting       service name (defaults to the linked one)
+  --command       path of the script, relative to the home of the hosting
+                  (required)
+  --frequency     schedule, as the first 5 fields of a crontab (required)
+  --language      runtime of the script, such as php8.2 or node18 (required)
+  --email         address receiving the errors of the script
+  --description
+  --disabled      create the cron disabled
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *CronAddCommand) Synopsis() string {
+	return "Create a cron"
+}
+
+func (c *CronAddCommand) Run(args []string) int {
+	var hosting string
+	var disabled bool
+	var cron api.Cron
+
+	flags := flag.NewFlagSet("cron add", flag.ExitOnError)
+
+	flags.StringVar(&hosting, "hosting", "", "")
+	flags.StringVar(&cron.Command, "command", "", "")
+	flags.StringVar(&cron.Frequency, "frequency", "", "")
+	flags.StringVar(&cron.Language, "language", "", "")
+	flags.StringVar(&cron.Email, "email", "", "")
+	flags.StringVar(&cron.Description, "description", "", "")
+	flags.BoolVar(&disabled, "disabled", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	required := []struct{ name, value string }{
+		{"command", cron.Command},
+		{"frequency", cron.Frequency},
+		{"language", cron.Language},
+	}
+
+	for _, r := range required {
+		if r.value == "" {
+			fmt.Printf("missing flag --%s\n", r.name)
+			return 1
+		}
+	}
+
+	if err := flow.ValidateFrequency(cron.Frequency); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	cron.Status = api.CronEnabled
+	if disabled {
+		cron.Status = api.CronDisabled
+	}
+
+	if hosting == "" {
+		link, err := c.EnsureLink()
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		hosting = link.Hosting
+	}
+
+	client, err := c.LoggedClient()
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if err := client.CreateCron(hosting, &cron); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	fmt.Printf("Cron %s created\n", cmdutil.Highlight(cron.Command))
+
+	return 0
+}
@@ -0,0 +1,31 @@
+package command
+
+import (
+	"strings"
+
+	"go.mlcdf.fr/owh/internal/api"
+)
+
+type CronDisableCommand struct {
+	App
+}
+
+func (c *CronDisableCommand) Help() string {
+	helpText := `
+Usage: owh cron disable [<options>] [ID]
+
+  Disables a cron.
+
+Options:
+  --hosting       service name (defaults to the linked one)
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *CronDisableCommand) Synopsis() string {
+	return "Disable a cron"
+}
+
+func (c *CronDisableCommand) Run(args []string) int {
+	return setCronStatus(&c.App, args, "cron disable", api.CronDisabled)
+}
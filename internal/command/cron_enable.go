@@ -0,0 +1,31 @@
+package command
+
+import (
+	"strings"
+
+	"go.mlcdf.fr/owh/internal/api"
+)
+
+type CronEnableCommand struct {
+	App
+}
+
+func (c *CronEnableCommand) Help() string {
+	helpText := `
+Usage: owh cron enable [<options>] [ID]
+
+  Enables a cron.
+
+Options:
+  --hosting       service name (defaults to the linked one)
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *CronEnableCommand) Synopsis() string {
+	return "Enable a cron"
+}
+
+func (c *CronEnableCommand) Run(args []string) int {
+	return setCronStatus(&c.App, args, "cron enable", api.CronEnabled)
+}
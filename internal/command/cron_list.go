@@ -0,0 +1,90 @@
+package command
+
+import (
+	"flag"
+	"strconv"
+	"strings"
+)
+
+type CronListCommand struct {
+	App
+}
+
+func (c *CronListCommand) Help() string {
+	helpText := `
+Usage: owh cron list [<options>]
+
+  Lists the crons of the hosting.
+
+Options:
+  --hosting       service name (defaults to the linked one)
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *CronListCommand) Synopsis() string {
+	return "List crons"
+}
+
+func (c *CronListCommand) Run(args []string) int {
+	var hosting string
+
+	flags := flag.NewFlagSet("cron list", flag.ExitOnError)
+
+	flags.StringVar(&hosting, "hosting", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if hosting == "" {
+		link, err := c.EnsureLink()
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		hosting = link.Hosting
+	}
+
+	client, err := c.LoggedClient()
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	crons, err := client.Crons(hosting)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if len(crons) == 0 {
+		c.View.Println("No cron found")
+		return 0
+	}
+
+	tables := make([][]string, 0)
+
+	for _, cron := range crons {
+		email := cron.Email
+		if email == "" {
+			email = "-"
+		}
+
+		row := []string{
+			strconv.FormatInt(cron.ID, 10),
+			cron.Command,
+			cron.Language,
+			cron.Frequency,
+			email,
+			cron.Status,
+			cron.Description,
+		}
+		tables = append(tables, row)
+	}
+
+	err = c.View.Table("", tables, "ID", "Command", "Language", "Frequency", "Email", "Status", "Description")
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	return 0
+}
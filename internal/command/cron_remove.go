@@ -0,0 +1,86 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"go.mlcdf.fr/owh/internal/cmdutil"
+)
+
+type CronRemoveCommand struct {
+	App
+}
+
+func (c *CronRemoveCommand) Help() string {
+	helpText := `
+Usage: owh cron remove [<options>] [ID]
+
+  Removes a cron.
+
+Options:
+  --hosting       service name (defaults to the linked one)
+  --yes
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *CronRemoveCommand) Synopsis() string {
+	return "Remove a cron"
+}
+
+func (c *CronRemoveCommand) Run(args []string) int {
+	var hosting string
+	var yes bool
+
+	flags := flag.NewFlagSet("cron remove", flag.ExitOnError)
+
+	flags.StringVar(&hosting, "hosting", "", "")
+	flags.BoolVar(&yes, "yes", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if hosting == "" {
+		link, err := c.EnsureLink()
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		hosting = link.Hosting
+	}
+
+	client, err := c.LoggedClient()
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	cron, err := selectCron(client, hosting, flags.Arg(0), c.IsInteractive)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if c.IsInteractive && !yes {
+		var shouldContinue bool
+
+		prompt := &survey.Confirm{Message: fmt.Sprintf("Are you sure you want to remove the cron %d running %s", cron.ID, cron.Command)}
+
+		if err := survey.AskOne(prompt, &shouldContinue); err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		if !shouldContinue {
+			return 2
+		}
+	}
+
+	if err := client.DeleteCron(hosting, cron.ID); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	fmt.Printf("Cron %s removed\n", cmdutil.Highlight(cron.Command))
+
+	return 0
+}
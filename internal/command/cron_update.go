@@ -0,0 +1,103 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"go.mlcdf.fr/owh/internal/flow"
+)
+
+type CronUpdateCommand struct {
+	App
+}
+
+func (c *CronUpdateCommand) Help() string {
+	helpText := `
+Usage: owh cron update [<options>] [ID]
+
+  Changes the settings of a cron. The settings without flag are kept.
+
+Options:
+  --hosting       service name (defaults to the linked one)
+  --command       path of the script, relative to the home of the hosting
+  --frequency     schedule, as the first 5 fields of a crontab
+  --language      runtime of the script, such as php8.2 or node18
+  --email         address receiving the errors of the script, none when empty
+  --description
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *CronUpdateCommand) Synopsis() string {
+	return "Change the settings of a cron"
+}
+
+func (c *CronUpdateCommand) Run(args []string) int {
+	var hosting string
+	var command string
+	var frequency string
+	var language string
+	var email string
+	var description string
+
+	flags := flag.NewFlagSet("cron update", flag.ExitOnError)
+
+	flags.StringVar(&hosting, "hosting", "", "")
+	flags.StringVar(&command, "command", "", "")
+	flags.StringVar(&frequency, "frequency", "", "")
+	flags.StringVar(&language, "language", "", "")
+	flags.StringVar(&email, "email", "", "")
+	flags.StringVar(&description, "description", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if hosting == "" {
+		link, err := c.EnsureLink()
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		hosting = link.Hosting
+	}
+
+	client, err := c.LoggedClient()
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	cron, err := selectCron(client, hosting, flags.Arg(0), c.IsInteractive)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	// only the flags given are changed, an empty value included
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "command":
+			cron.Command = command
+		case "frequency":
+			cron.Frequency = frequency
+		case "language":
+			cron.Language = language
+		case "email":
+			cron.Email = email
+		case "description":
+			cron.Description = description
+		}
+	})
+
+	if err := flow.ValidateFrequency(cron.Frequency); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if err := client.UpdateCron(hosting, cron); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	fmt.Printf("Cron %d updated\n", cron.ID)
+
+	return 0
+}
@@ -0,0 +1,119 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"go.mlcdf.fr/owh/internal/api"
+)
+
+type DatabasesCommand struct {
+	App
+}
+
+func (c *DatabasesCommand) Help() string {
+	helpText := `
+Usage: owh databases [<command>] [<options>]
+
+  Manages the databases of the hosting.
+  Lists all the databases when run without subcommand.
+
+Options:
+  --hosting       service name (defaults to the linked one)
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *DatabasesCommand) Synopsis() string {
+	return "Manage databases"
+}
+
+func (c *DatabasesCommand) Run(args []string) int {
+	var hosting string
+
+	flags := flag.NewFlagSet("databases", flag.ExitOnError)
+
+	flags.StringVar(&hosting, "hosting", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if hosting == "" {
+		link, err := c.EnsureLink()
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		hosting = link.Hosting
+	}
+
+	client, err := c.LoggedClient()
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	databases, err := client.Databases(hosting)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if len(databases) == 0 {
+		c.View.Println("No database found")
+		return 0
+	}
+
+	tables := make([][]string, 0)
+
+	for _, database := range databases {
+		row := []string{
+			database.Name,
+			database.Type + " " + database.Version,
+			database.Server,
+			strconv.Itoa(database.Port),
+			database.User,
+			fmt.Sprintf("%s / %s", database.QuotaUsed.String(), database.QuotaSize.String()),
+			database.State,
+		}
+		tables = append(tables, row)
+	}
+
+	err = c.View.Table("", tables, "Name", "Type", "Server", "Port", "User", "Quota", "State")
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	return 0
+}
+
+// selectDatabase returns name, or else prompts for one of the databases of
+// the hosting.
+func selectDatabase(client *api.Client, hosting string, name string, isInteractive bool) (string, error) {
+	if name != "" {
+		return name, nil
+	}
+
+	if !isInteractive {
+		return "", fmt.Errorf("missing positional argument NAME")
+	}
+
+	names, err := client.ListDatabases(hosting)
+	if err != nil {
+		return "", err
+	}
+
+	if len(names) == 0 {
+		return "", fmt.Errorf("no database found on hosting %s", hosting)
+	}
+
+	prompt := &survey.Select{Message: "Database", Options: names}
+
+	if err := survey.AskOne(prompt, &name); err != nil {
+		return "", err
+	}
+
+	return name, nil
+}
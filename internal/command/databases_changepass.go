@@ -0,0 +1,87 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"go.mlcdf.fr/owh/internal/cmdutil"
+	"go.mlcdf.fr/owh/internal/flow"
+)
+
+type DatabasesChangePassCommand struct {
+	App
+}
+
+func (c *DatabasesChangePassCommand) Help() string {
+	helpText := `
+Usage: owh databases changepass [<options>] [NAME]
+
+  Changes the password of the user of a database.
+
+Options:
+  --hosting       service name (defaults to the linked one)
+  --password      new password (generated when empty)
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *DatabasesChangePassCommand) Synopsis() string {
+	return "Change the password of a database"
+}
+
+func (c *DatabasesChangePassCommand) Run(args []string) int {
+	var hosting string
+	var password string
+
+	flags := flag.NewFlagSet("databases changepass", flag.ExitOnError)
+
+	flags.StringVar(&hosting, "hosting", "", "")
+	flags.StringVar(&password, "password", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if hosting == "" {
+		link, err := c.EnsureLink()
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		hosting = link.Hosting
+	}
+
+	client, err := c.LoggedClient()
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	name, err := selectDatabase(client, hosting, flags.Arg(0), c.IsInteractive)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	generated := password == ""
+	if generated {
+		password = flow.GenPassword()
+	}
+
+	id, err := client.ChangeDatabasePassword(hosting, name, password)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	err = flow.WaitTaskDone(client, c.View, hosting, id, fmt.Sprintf("Changing the password of database %s", name))
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	fmt.Printf("Password of database %s changed\n", cmdutil.Highlight(name))
+
+	if generated {
+		fmt.Printf("Password: %s\n", password)
+	}
+
+	return 0
+}
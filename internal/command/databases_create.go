@@ -0,0 +1,147 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"go.mlcdf.fr/owh/internal/api"
+	"go.mlcdf.fr/owh/internal/cmdutil"
+	"go.mlcdf.fr/owh/internal/flow"
+)
+
+type DatabasesCreateCommand struct {
+	App
+}
+
+func (c *DatabasesCreateCommand) Help() string {
+	helpText := `
+Usage: owh databases create [<options>]
+
+  Creates a database and waits for it to be ready.
+
+Options:
+  --hosting       service name (defaults to the linked one)
+  --user          user of the database, also used as its name (required)
+  --password      password of the user (generated when empty)
+  --type          mysql, postgresql or redis (default: mysql)
+  --version       version of the database engine (defaults to the latest)
+  --capability    offer the database is created with (defaults to the first
+                  one available)
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *DatabasesCreateCommand) Synopsis() string {
+	return "Create a database"
+}
+
+func (c *DatabasesCreateCommand) Run(args []string) int {
+	var hosting string
+	var payload api.DatabaseCreation
+
+	flags := flag.NewFlagSet("databases create", flag.ExitOnError)
+
+	flags.StringVar(&hosting, "hosting", "", "")
+	flags.StringVar(&payload.User, "user", "", "")
+	flags.StringVar(&payload.Password, "password", "", "")
+	flags.StringVar(&payload.Type, "type", "mysql", "")
+	flags.StringVar(&payload.Version, "version", "", "")
+	flags.StringVar(&payload.Capability, "capability", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if hosting == "" {
+		link, err := c.EnsureLink()
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		hosting = link.Hosting
+	}
+
+	if payload.User == "" {
+		if !c.IsInteractive {
+			fmt.Println("missing flag --user")
+			return 1
+		}
+
+		prompt := &survey.Input{Message: "Database user (also used as its name)"}
+		if err := survey.AskOne(prompt, &payload.User, survey.WithValidator(survey.Required)); err != nil {
+			return c.View.PrintErr(err)
+		}
+	}
+
+	client, err := c.LoggedClient()
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	capabilities, err := client.DatabaseCapabilities(hosting)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	capability, err := selectCapability(capabilities, payload.Capability)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	payload.Capability = capability.Type
+	payload.Quota = int(capability.Quota.Value)
+
+	if payload.Version == "" {
+		versions, err := client.DatabaseVersions(hosting, payload.Type)
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		if len(versions) > 0 {
+			payload.Version = versions[0]
+		}
+	}
+
+	generated := payload.Password == ""
+	if generated {
+		payload.Password = flow.GenPassword()
+	}
+
+	id, err := client.CreateDatabase(hosting, &payload)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	err = flow.WaitTaskDone(client, c.View, hosting, id, fmt.Sprintf("Creating database %s", payload.User))
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	fmt.Printf("Database %s created\n", cmdutil.Highlight(payload.User))
+
+	if generated {
+		fmt.Printf("Password: %s\n", payload.Password)
+	}
+
+	return 0
+}
+
+// selectCapability returns the capability named name, or the first one with
+// databases left to create when name is empty.
+func selectCapability(capabilities []api.DatabaseCapability, name string) (*api.DatabaseCapability, error) {
+	for _, capability := range capabilities {
+		capability := capability
+
+		if name == "" && capability.Available > 0 || name != "" && capability.Type == name {
+			return &capability, nil
+		}
+	}
+
+	if name != "" {
+		return nil, fmt.Errorf("capability %s is not available on this hosting", name)
+	}
+
+	return nil, fmt.Errorf("no database left to create on this hosting")
+}
@@ -0,0 +1,93 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"go.mlcdf.fr/owh/internal/cmdutil"
+	"go.mlcdf.fr/owh/internal/flow"
+)
+
+type DatabasesDeleteCommand struct {
+	App
+}
+
+func (c *DatabasesDeleteCommand) Help() string {
+	helpText := `
+Usage: owh databases delete [<options>] [NAME]
+
+  Deletes a database and its content, and waits for it to be done.
+
+Options:
+  --hosting       service name (defaults to the linked one)
+  --yes
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *DatabasesDeleteCommand) Synopsis() string {
+	return "Delete a database"
+}
+
+func (c *DatabasesDeleteCommand) Run(args []string) int {
+	var hosting string
+	var yes bool
+
+	flags := flag.NewFlagSet("databases delete", flag.ExitOnError)
+
+	flags.StringVar(&hosting, "hosting", "", "")
+	flags.BoolVar(&yes, "yes", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if hosting == "" {
+		link, err := c.EnsureLink()
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		hosting = link.Hosting
+	}
+
+	client, err := c.LoggedClient()
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	name, err := selectDatabase(client, hosting, flags.Arg(0), c.IsInteractive)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if c.IsInteractive && !yes {
+		var shouldContinue bool
+
+		prompt := &survey.Confirm{Message: fmt.Sprintf("Are you sure you want to delete the database %s and its content", name)}
+
+		if err := survey.AskOne(prompt, &shouldContinue); err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		if !shouldContinue {
+			return 2
+		}
+	}
+
+	id, err := client.DeleteDatabase(hosting, name)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	err = flow.WaitTaskDone(client, c.View, hosting, id, fmt.Sprintf("Deleting database %s", name))
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	fmt.Printf("Database %s deleted\n", cmdutil.Highlight(name))
+
+	return 0
+}
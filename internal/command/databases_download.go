@@ -0,0 +1,164 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"go.mlcdf.fr/owh/internal/api"
+	"go.mlcdf.fr/owh/internal/cmdutil"
+	"golang.org/x/xerrors"
+)
+
+type DatabasesDownloadCommand struct {
+	App
+}
+
+func (c *DatabasesDownloadCommand) Help() string {
+	helpText := `
+Usage: owh databases download [<options>] [NAME]
+
+  Downloads a dump of a database.
+
+Options:
+  --hosting       service name (defaults to the linked one)
+  --dump          ID of the dump, as listed by 'owh databases dumps'
+                  (defaults to the most recent one)
+  --output        file the dump is written to (defaults to the name of the
+                  dump, in the current directory)
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *DatabasesDownloadCommand) Synopsis() string {
+	return "Download a dump of a database"
+}
+
+func (c *DatabasesDownloadCommand) Run(args []string) int {
+	var hosting string
+	var dumpID int64
+	var output string
+
+	flags := flag.NewFlagSet("databases download", flag.ExitOnError)
+
+	flags.StringVar(&hosting, "hosting", "", "")
+	flags.Int64Var(&dumpID, "dump", 0, "")
+	flags.StringVar(&output, "output", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if hosting == "" {
+		link, err := c.EnsureLink()
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		hosting = link.Hosting
+	}
+
+	client, err := c.LoggedClient()
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	name, err := selectDatabase(client, hosting, flags.Arg(0), c.IsInteractive)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	dumps, err := client.DatabaseDumps(hosting, name)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	dump, err := findDump(dumps, dumpID)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if output == "" {
+		output = dumpFilename(dump)
+	}
+
+	c.View.StartSpinner(fmt.Sprintf("Downloading dump %d of database %s", dump.ID, name))
+	err = downloadURL(c.HTTPClient, dump.URL, output)
+	c.View.StopSpinner()
+
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	fmt.Printf("Dump downloaded to %s\n", cmdutil.Highlight(output))
+
+	return 0
+}
+
+// findDump returns the dump with the given id, or the most recent one when
+// id is 0. dumps are sorted from the most recent.
+func findDump(dumps []api.DatabaseDump, id int64) (*api.DatabaseDump, error) {
+	if len(dumps) == 0 {
+		return nil, xerrors.New("no dump found, create one with: owh databases dump")
+	}
+
+	if id == 0 {
+		return &dumps[0], nil
+	}
+
+	for i := range dumps {
+		if dumps[i].ID == id {
+			return &dumps[i], nil
+		}
+	}
+
+	return nil, xerrors.Errorf("no dump with ID %d", id)
+}
+
+// dumpFilename returns the name of the file of the dump, as found in its URL.
+func dumpFilename(dump *api.DatabaseDump) string {
+	if u, err := url.Parse(dump.URL); err == nil {
+		if name := path.Base(u.Path); name != "." && name != "/" {
+			return name
+		}
+	}
+
+	return fmt.Sprintf("%s-%d.sql.gz", dump.DatabaseName, dump.ID)
+}
+
+// downloadURL writes the body of the response to url to dest. dest is
+// replaced only once the download is complete.
+func downloadURL(httpClient *http.Client, url string, dest string) error {
+	res, err := httpClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return xerrors.Errorf("failed to download %s: %s", url, res.Status)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(dest), "."+filepath.Base(dest)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := io.Copy(f, res.Body); err != nil {
+		f.Close()
+		return xerrors.Errorf("failed to download %s: %w", url, err)
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), dest)
+}
@@ -0,0 +1,90 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"go.mlcdf.fr/owh/internal/cmdutil"
+	"go.mlcdf.fr/owh/internal/flow"
+	"golang.org/x/exp/slices"
+)
+
+var dumpDates = []string{"now", "daily.1", "weekly.1"}
+
+type DatabasesDumpCommand struct {
+	App
+}
+
+func (c *DatabasesDumpCommand) Help() string {
+	helpText := `
+Usage: owh databases dump [<options>] [NAME]
+
+  Dumps a database and waits for the dump to be available.
+
+  The dumps are listed with 'owh databases dumps' and downloaded with
+  'owh databases download'.
+
+Options:
+  --hosting       service name (defaults to the linked one)
+  --date          now, or daily.1 and weekly.1 to dump the backup of the
+                  previous day or week (default: now)
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *DatabasesDumpCommand) Synopsis() string {
+	return "Dump a database"
+}
+
+func (c *DatabasesDumpCommand) Run(args []string) int {
+	var hosting string
+	var date string
+
+	flags := flag.NewFlagSet("databases dump", flag.ExitOnError)
+
+	flags.StringVar(&hosting, "hosting", "", "")
+	flags.StringVar(&date, "date", "now", "")
+
+	if err := flags.Parse(args); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if !slices.Contains(dumpDates, date) {
+		fmt.Printf("invalid --date %s, expected one of %s\n", date, strings.Join(dumpDates, ", "))
+		return 1
+	}
+
+	if hosting == "" {
+		link, err := c.EnsureLink()
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		hosting = link.Hosting
+	}
+
+	client, err := c.LoggedClient()
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	name, err := selectDatabase(client, hosting, flags.Arg(0), c.IsInteractive)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	id, err := client.DumpDatabase(hosting, name, date)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	err = flow.WaitTaskDone(client, c.View, hosting, id, fmt.Sprintf("Dumping database %s", name))
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	fmt.Printf("Database %s dumped\n", cmdutil.Highlight(name))
+
+	return 0
+}
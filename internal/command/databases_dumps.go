@@ -0,0 +1,88 @@
+package command
+
+import (
+	"flag"
+	"strconv"
+	"strings"
+)
+
+type DatabasesDumpsCommand struct {
+	App
+}
+
+func (c *DatabasesDumpsCommand) Help() string {
+	helpText := `
+Usage: owh databases dumps [<options>] [NAME]
+
+  Lists the dumps of a database, the most recent first.
+
+Options:
+  --hosting       service name (defaults to the linked one)
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *DatabasesDumpsCommand) Synopsis() string {
+	return "List the dumps of a database"
+}
+
+func (c *DatabasesDumpsCommand) Run(args []string) int {
+	var hosting string
+
+	flags := flag.NewFlagSet("databases dumps", flag.ExitOnError)
+
+	flags.StringVar(&hosting, "hosting", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if hosting == "" {
+		link, err := c.EnsureLink()
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		hosting = link.Hosting
+	}
+
+	client, err := c.LoggedClient()
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	name, err := selectDatabase(client, hosting, flags.Arg(0), c.IsInteractive)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	dumps, err := client.DatabaseDumps(hosting, name)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if len(dumps) == 0 {
+		c.View.Println("No dump found")
+		return 0
+	}
+
+	tables := make([][]string, 0)
+
+	for _, dump := range dumps {
+		row := []string{
+			strconv.FormatInt(dump.ID, 10),
+			dump.Type,
+			dump.Status,
+			dump.SnapshotDate.Local().Format(deploymentDateFormat),
+			dump.DeletionDate.Local().Format(deploymentDateFormat),
+		}
+		tables = append(tables, row)
+	}
+
+	err = c.View.Table("", tables, "ID", "Type", "Status", "Date", "Expires")
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	return 0
+}
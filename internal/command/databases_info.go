@@ -0,0 +1,79 @@
+package command
+
+import (
+	"flag"
+	"strconv"
+	"strings"
+
+	"go.mlcdf.fr/owh/internal/view"
+)
+
+type DatabasesInfoCommand struct {
+	App
+}
+
+func (c *DatabasesInfoCommand) Help() string {
+	helpText := `
+Usage: owh databases info [<options>] [NAME]
+
+  Shows the details of a database: its server, port, user and quota.
+
+Options:
+  --hosting       service name (defaults to the linked one)
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *DatabasesInfoCommand) Synopsis() string {
+	return "Show the details of a database"
+}
+
+func (c *DatabasesInfoCommand) Run(args []string) int {
+	var hosting string
+
+	flags := flag.NewFlagSet("databases info", flag.ExitOnError)
+
+	flags.StringVar(&hosting, "hosting", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if hosting == "" {
+		link, err := c.EnsureLink()
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		hosting = link.Hosting
+	}
+
+	client, err := c.LoggedClient()
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	name, err := selectDatabase(client, hosting, flags.Arg(0), c.IsInteractive)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	database, err := client.GetDatabase(hosting, name)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	c.View.VerticalTable(database.Name, []view.LabelValue{
+		{Label: "Type", Value: database.Type + " " + database.Version},
+		{Label: "Server", Value: database.Server},
+		{Label: "Port", Value: strconv.Itoa(database.Port)},
+		{Label: "User", Value: database.User},
+		{Label: "Quota used", Value: database.QuotaUsed.String()},
+		{Label: "Quota size", Value: database.QuotaSize.String()},
+		{Label: "Mode", Value: database.Mode},
+		{Label: "State", Value: database.State},
+		{Label: "Admin", Value: database.GUIURL},
+	})
+
+	return 0
+}
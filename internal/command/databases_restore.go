@@ -0,0 +1,183 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"go.mlcdf.fr/owh/internal/api"
+	"go.mlcdf.fr/owh/internal/cmdutil"
+	"go.mlcdf.fr/owh/internal/flow"
+	"go.mlcdf.fr/owh/internal/view"
+	"go.mlcdf.fr/sally/logging"
+)
+
+type DatabasesRestoreCommand struct {
+	App
+}
+
+func (c *DatabasesRestoreCommand) Help() string {
+	helpText := `
+Usage: owh databases restore [<options>] [NAME]
+
+  Replaces the content of a database with one of its dumps, or runs a local
+  SQL file in it.
+
+Options:
+  --hosting       service name (defaults to the linked one)
+  --dump          ID of the dump to restore, as listed by 'owh databases dumps'
+                  (defaults to the most recent one)
+  --file          local SQL file to import instead of a dump (.sql, .sql.gz)
+  --flush         drop the tables of the database before importing --file
+  --yes
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *DatabasesRestoreCommand) Synopsis() string {
+	return "Restore a dump or import a SQL file in a database"
+}
+
+func (c *DatabasesRestoreCommand) Run(args []string) int {
+	var hosting string
+	var dumpID int64
+	var file string
+	var flush bool
+	var yes bool
+
+	flags := flag.NewFlagSet("databases restore", flag.ExitOnError)
+
+	flags.StringVar(&hosting, "hosting", "", "")
+	flags.Int64Var(&dumpID, "dump", 0, "")
+	flags.StringVar(&file, "file", "", "")
+	flags.BoolVar(&flush, "flush", false, "")
+	flags.BoolVar(&yes, "yes", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if file != "" && dumpID != 0 {
+		fmt.Println("--dump and --file can't be used together")
+		return 1
+	}
+
+	if hosting == "" {
+		link, err := c.EnsureLink()
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		hosting = link.Hosting
+	}
+
+	client, err := c.LoggedClient()
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	name, err := selectDatabase(client, hosting, flags.Arg(0), c.IsInteractive)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	var source string
+	var dump *api.DatabaseDump
+
+	if file != "" {
+		source = file
+	} else {
+		dumps, err := client.DatabaseDumps(hosting, name)
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		dump, err = findDump(dumps, dumpID)
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		source = fmt.Sprintf("the dump of %s", dump.SnapshotDate.Local().Format(deploymentDateFormat))
+	}
+
+	if c.IsInteractive && !yes {
+		var shouldContinue bool
+
+		prompt := &survey.Confirm{Message: fmt.Sprintf("Are you sure you want to restore %s in database %s on hosting %s", source, name, hosting)}
+
+		if err := survey.AskOne(prompt, &shouldContinue); err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		if !shouldContinue {
+			return 2
+		}
+	}
+
+	if file != "" {
+		err = importFile(client, c.View, hosting, name, file, flush)
+	} else {
+		err = restoreDump(client, c.View, hosting, name, dump)
+	}
+
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	fmt.Printf("Database %s restored from %s\n", cmdutil.Highlight(name), source)
+
+	return 0
+}
+
+func restoreDump(client *api.Client, v *view.View, hosting string, name string, dump *api.DatabaseDump) error {
+	id, err := client.RestoreDatabaseDump(hosting, name, dump.ID)
+	if err != nil {
+		return err
+	}
+
+	return flow.WaitTaskDone(client, v, hosting, id, fmt.Sprintf("Restoring dump %d in database %s", dump.ID, name))
+}
+
+// importFile uploads the SQL file as a document of the account, which the
+// hosting then imports in the database.
+func importFile(client *api.Client, v *view.View, hosting string, name string, file string, flush bool) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	document, err := client.CreateDocument(filepath.Base(file))
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err := client.DeleteDocument(document.ID); err != nil {
+			logging.Debugf("failed to delete document %s: %s", document.ID, err)
+		}
+	}()
+
+	v.StartSpinner(fmt.Sprintf("Uploading %s", file))
+	err = client.UploadDocument(document, f, info.Size())
+	v.StopSpinner()
+
+	if err != nil {
+		return err
+	}
+
+	id, err := client.ImportDatabase(hosting, name, document.ID, flush)
+	if err != nil {
+		return err
+	}
+
+	return flow.WaitTaskDone(client, v, hosting, id, fmt.Sprintf("Importing %s in database %s", file, name))
+}
@@ -1,16 +1,30 @@
 package command
 
 import (
+	"context"
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/charmbracelet/lipgloss"
 	"go.mlcdf.fr/owh/internal/cmdutil"
 	"go.mlcdf.fr/owh/internal/config"
+	"go.mlcdf.fr/owh/internal/dotenv"
 	"go.mlcdf.fr/owh/internal/flow"
+	"go.mlcdf.fr/owh/internal/ignore"
+	"go.mlcdf.fr/owh/internal/ovhconfig"
+	"go.mlcdf.fr/owh/internal/remote"
+	"go.mlcdf.fr/owh/internal/unit"
+	"go.mlcdf.fr/owh/internal/view"
+	"go.mlcdf.fr/owh/internal/watch"
+	"golang.org/x/xerrors"
 )
 
 type DeployCommand struct {
@@ -19,13 +33,66 @@ type DeployCommand struct {
 
 func (c *DeployCommand) Help() string {
 	helpText := `
-Usage: owh deploy [options] DIR
+Usage: owh deploy [options] [DIR]
 
   Deploys the linked website to OVHcloud Web Hosting.
   If the directory is not linked, it'll ask to linked it to a hosting first.
 
+  Files are uploaded to a new release directory, then the website is switched
+  to it in one step. Use 'owh releases' and 'owh rollback' to manage releases.
+  Each deployment is recorded on the hosting, see 'owh deploys'.
+
 Options:
-  --www       If present, also attach www/non-www domain
+  --www                 If present, also attach www/non-www domain
+  --keep                Number of releases to keep on the hosting (default: 5)
+  --concurrency         Number of files uploaded in parallel (default: 8)
+  --requests-per-file   Number of concurrent SFTP requests per file (default: 64)
+  --dry-run             Print the changes to make without uploading anything,
+                        running the build or setting environment variables
+  --json                With --dry-run, print the changes as JSON
+  --list-files          List the files that would be deployed and exit
+  --preserve            Remote path never deleted nor overwritten (repeatable)
+  --skip-build          Don't run the build command of .owh.json
+  --env-file            .env file whose variables are set on the hosting
+                        before uploading the files
+  --env-plain           With --env-file, create the new variables as
+                        non-secret
+  --env-prune           With --env-file, remove the variables missing from
+                        the file
+  --watch               Once deployed, upload the changes made to DIR until
+                        interrupted
+
+  When .owh.json defines a "build" command, it's run first and its "output"
+  directory is deployed, unless DIR is given. The .ovhconfig file of the
+  current directory, see 'owh runtime set', is copied into the output.
+
+  The "hooks" of .owh.json are run on the hosting: "pre_deploy" commands from
+  the release directory before the website is switched to it, "post_deploy"
+  commands once it's live. A failing command aborts the deployment.
+
+  Files matching the patterns of the .owhignore file of DIR, or of the
+  "ignore" list of .owh.json, are neither uploaded nor deleted from the
+  hosting. Dotfiles (except .htaccess, .ovhconfig and .well-known),
+  node_modules and __pycache__ are ignored by default.
+
+  With --env-file, the environment variables of the hosting are set to the
+  values of the file, like 'owh env import' does: the new ones are created
+  as secrets unless --env-plain is passed, the others keep their type. The
+  variables missing from the file are kept, unless --env-prune is passed.
+
+  When .owh.json lists "crons", the crons of the hosting are created, updated
+  and removed to match them once the release is live. Only the crons of the
+  website directory are removed (see 'owh cron').
+
+  With --watch, the changed, created or deleted files are pushed to the live
+  release as they're saved, without creating new releases nor running the
+  build and the hooks again.
+
+  Remote paths matching the patterns of --preserve or of the "preserve" list
+  of .owh.json (for example wp-content/uploads/) are kept as is, even if
+  they are missing locally. They are copied to the new release when the
+  deployment starts: files written to them while it runs, such as uploads,
+  are not part of the release.
 `
 	return strings.TrimSpace(helpText)
 }
@@ -36,23 +103,72 @@ func (c *DeployCommand) Synopsis() string {
 
 func (c *DeployCommand) Run(args []string) int {
 	var www bool
+	var dryRun bool
+	var jsonOutput bool
+	var listFiles bool
+	var preserve cmdutil.StringSlice
+	var skipBuild bool
+	var watchChanges bool
+	var envFile string
+	var envPlain bool
+	var envPrune bool
+	var keep int
 	var directory string
+	var opts remote.SyncOptions
+
+	start := time.Now()
 
 	flags := flag.NewFlagSet("deploy", flag.ExitOnError)
 
 	flags.BoolVar(&www, "www", false, "")
+	flags.IntVar(&keep, "keep", remote.DefaultKeepReleases, "")
+	flags.IntVar(&opts.Concurrency, "concurrency", remote.DefaultConcurrency, "")
+	flags.IntVar(&opts.RequestsPerFile, "requests-per-file", remote.DefaultRequestsPerFile, "")
+	flags.BoolVar(&dryRun, "dry-run", false, "")
+	flags.BoolVar(&jsonOutput, "json", false, "")
+	flags.BoolVar(&listFiles, "list-files", false, "")
+	flags.Var(&preserve, "preserve", "")
+	flags.BoolVar(&skipBuild, "skip-build", false, "")
+	flags.BoolVar(&watchChanges, "watch", false, "")
+	flags.StringVar(&envFile, "env-file", "", "")
+	flags.BoolVar(&envPlain, "env-plain", false, "")
+	flags.BoolVar(&envPrune, "env-prune", false, "")
 
 	if err := flags.Parse(args); err != nil {
 		return c.View.PrintErr(err)
 	}
 
-	if directory = flags.Arg(0); directory == "" {
-		var err error
+	if watchChanges && (dryRun || listFiles) {
+		return c.View.PrintErr(xerrors.New("--watch can't be used with --dry-run or --list-files"))
+	}
+
+	if (envPlain || envPrune) && envFile == "" {
+		return c.View.PrintErr(xerrors.New("--env-plain and --env-prune require --env-file"))
+	}
+
+	if listFiles {
+		// the ignore patterns of the link are used when the directory is linked
+		l, _ := c.App.EnsureLink()
+
+		directory, err := deployDirectory(flags.Arg(0), l)
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
 
-		directory, err = os.Getwd()
+		matcher, err := ignore.Load(directory, l.Ignore)
 		if err != nil {
 			return c.View.PrintErr(err)
 		}
+
+		files, err := remote.LocalFiles(directory, matcher)
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		for _, file := range files {
+			c.View.Println(file.Path)
+		}
+		return 0
 	}
 
 	ovhapi, err := c.LoggedClient()
@@ -60,8 +176,6 @@ func (c *DeployCommand) Run(args []string) int {
 		return c.View.PrintErr(err)
 	}
 
-	fmt.Printf("Deploying %s\n", directory)
-
 	l, err := c.App.EnsureLink()
 
 	if err != nil {
@@ -103,19 +217,195 @@ func (c *DeployCommand) Run(args []string) int {
 		}
 	}
 
+	// keep stdout clean when it's used for the JSON output
+	var stdout io.Writer = os.Stdout
+	if jsonOutput {
+		stdout = os.Stderr
+	}
+
+	var vars []dotenv.Var
+	if envFile != "" {
+		vars, err = dotenv.Load(envFile)
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+	}
+
+	if l.Build != nil && l.Build.Command != "" && !skipBuild {
+		if dryRun {
+			fmt.Fprintf(stdout, "Build skipped with --dry-run, %s is compared as is\n", l.Build.Output)
+		} else if err := flow.Build(l.Build, stdout, os.Stderr); err != nil {
+			fmt.Printf("failed to build: %v\n", err)
+			return 1
+		}
+	}
+
+	// checked before anything is uploaded, the crons are updated once the
+	// release is live
+	if err := flow.ValidateCrons(l.Crons); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	directory, err = deployDirectory(flags.Arg(0), l)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if flags.Arg(0) == "" && !dryRun {
+		if err := copyOvhConfig(".", l); err != nil {
+			return c.View.PrintErr(err)
+		}
+	}
+
+	if !jsonOutput {
+		fmt.Printf("Deploying %s\n", directory)
+	}
+
+	opts.Ignore, err = ignore.Load(directory, l.Ignore)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	opts.Preserve = ignore.New(append(l.Preserve, preserve...)...)
+	opts.PartsDir = remote.PartsPath(l.CanonicalDomain)
+
 	conn, err := flow.NewSSHClient(ovhapi, c.Config, c.View, c.IsInteractive, l.Hosting)
 	if err != nil {
 		fmt.Printf("failed to connect ssh: %v\n", err)
 		return 1
 	}
 
-	err = conn.Sync(directory, l.CanonicalDomain)
+	if dryRun {
+		plan, err := conn.Plan(directory, l.CanonicalDomain, opts)
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		if jsonOutput {
+			err = c.View.JSON(plan)
+		} else {
+			printShadowed(plan.Shadowed)
+			printPlan(c.View, plan)
+		}
+
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		if envFile != "" {
+			current, err := ovhapi.EnvVars(l.Hosting)
+			if err != nil {
+				return c.View.PrintErr(err)
+			}
+
+			printEnvPlan(view.New(stdout, false), flow.PlanEnv(current, vars, !envPlain, envPrune))
+		}
+		return 0
+	}
+
+	if envFile != "" {
+		changes, err := flow.ReconcileEnv(ovhapi, c.View, l.Hosting, vars, !envPlain, envPrune)
+		if err != nil {
+			fmt.Printf("failed to set environment variables: %v\n", err)
+			return 1
+		}
+
+		if !changes.IsEmpty() {
+			fmt.Printf(
+				"Environment variables updated (%d set, %d updated, %d removed)\n",
+				len(changes.Create),
+				len(changes.Update),
+				len(changes.Delete),
+			)
+		}
+
+		if len(changes.Create) > 0 && !envPlain {
+			fmt.Println("The new variables were created as secrets, pass --env-plain to create them as non-secret.")
+		}
+	}
+
+	release, err := conn.NewRelease(l.CanonicalDomain)
+	if err != nil {
+		fmt.Printf("failed to create release: %v\n", err)
+		return 1
+	}
+
+	releasePath := remote.ReleasePath(l.CanonicalDomain, release)
+
+	stats, err := conn.Sync(directory, releasePath, opts)
 	if err != nil {
 		fmt.Printf("failed to upload files: %v\n", err)
+
+		if err := conn.ForceRemove(releasePath); err != nil {
+			fmt.Printf("failed to clean up release %s: %v\n", release, err)
+		}
 		return 1
 	}
 
-	fmt.Printf("Files uploaded to ./%s\n", cmdutil.Highlight(l.CanonicalDomain))
+	printShadowed(stats.Plan.Shadowed)
+
+	fmt.Printf("Files uploaded to ./%s (%s)\n", cmdutil.Highlight(releasePath), stats)
+
+	if l.Hooks != nil {
+		if err := runHooks(conn, releasePath, l.Hooks.PreDeploy); err != nil {
+			fmt.Printf("pre-deploy command failed: %v\n", err)
+
+			if err := conn.ForceRemove(releasePath); err != nil {
+				fmt.Printf("failed to clean up release %s: %v\n", release, err)
+			}
+			return 1
+		}
+	}
+
+	err = conn.Activate(l.CanonicalDomain, release)
+	if err != nil {
+		fmt.Printf("failed to activate release %s: %v\n", release, err)
+
+		if err := conn.ForceRemove(releasePath); err != nil {
+			fmt.Printf("failed to clean up release %s: %v\n", release, err)
+		}
+		return 1
+	}
+
+	fmt.Printf("Release %s is live at ./%s\n", cmdutil.Highlight(release), cmdutil.Highlight(l.CanonicalDomain))
+
+	deployment := remote.NewDeployment(release, stats)
+	deployment.Duration = time.Since(start)
+	flow.DescribeDeployment(deployment, directory)
+
+	if err := conn.AppendHistory(l.CanonicalDomain, deployment); err != nil {
+		fmt.Printf("failed to record deployment: %v\n", err)
+	}
+
+	if l.Hooks != nil {
+		if err := runHooks(conn, l.CanonicalDomain, l.Hooks.PostDeploy); err != nil {
+			fmt.Printf("post-deploy command failed: %v\n", err)
+			fmt.Println("The release is live, run 'owh rollback' to switch back to the previous one.")
+			return 1
+		}
+	}
+
+	if l.Crons != nil {
+		// the release is live, a failure doesn't stop the deployment
+		changes, err := flow.ReconcileCrons(ovhapi, l.Hosting, l.Crons, l.CanonicalDomain)
+		if err != nil {
+			fmt.Printf("failed to update crons: %v\n", err)
+		} else if !changes.IsEmpty() {
+			fmt.Printf(
+				"Crons updated (%d created, %d updated, %d removed)\n",
+				len(changes.Create),
+				len(changes.Update),
+				len(changes.Delete),
+			)
+		}
+	}
+
+	pruned, err := conn.PruneReleases(l.CanonicalDomain, keep)
+	if err != nil {
+		fmt.Printf("failed to prune old releases: %v\n", err)
+	} else if len(pruned) > 0 {
+		fmt.Printf("Pruned %d old release(s)\n", len(pruned))
+	}
 
 	_, err = flow.AttachDomain(ovhapi, l.Hosting, l.CanonicalDomain, www)
 	if err != nil {
@@ -123,5 +413,160 @@ func (c *DeployCommand) Run(args []string) int {
 		return 1
 	}
 
+	if watchChanges {
+		return c.watch(conn, directory, l.CanonicalDomain, opts)
+	}
+
+	return 0
+}
+
+// watch pushes the changes made to directory to the live website dest until
+// interrupted.
+func (c *DeployCommand) watch(conn *remote.Client, directory string, dest string, opts remote.SyncOptions) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Printf("Watching %s for changes, press Ctrl-C to stop\n", directory)
+
+	w := watch.New(directory, opts.Ignore, watch.DefaultDebounce)
+
+	err := w.Run(ctx, func(paths []string) error {
+		if conn.Closed() {
+			if err := conn.Reconnect(ctx); err != nil {
+				fmt.Printf("failed to push changes: %v\n", err)
+				return nil
+			}
+		}
+
+		stats, err := conn.Push(directory, dest, paths, opts)
+		if err != nil {
+			fmt.Printf("failed to push changes: %v\n", err)
+			return nil
+		}
+
+		fmt.Printf("%s Changes pushed (%s)\n", time.Now().Format("15:04:05"), stats)
+
+		return nil
+	})
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
 	return 0
 }
+
+// runHooks runs the commands one after the other from dir on the hosting,
+// streaming their output. It stops at the first failure.
+func runHooks(conn *remote.Client, dir string, commands []string) error {
+	for _, command := range commands {
+		fmt.Printf("Running %s\n", cmdutil.Highlight(command))
+
+		if err := conn.Stream(dir, command, os.Stdout, os.Stderr); err != nil {
+			return xerrors.Errorf("%s: %w", command, err)
+		}
+	}
+
+	return nil
+}
+
+// deployDirectory returns the directory to deploy: arg when set, else the
+// output directory of the build, else the current directory.
+func deployDirectory(arg string, l *config.Link) (string, error) {
+	if arg != "" {
+		return arg, nil
+	}
+
+	if l != nil && l.Build != nil && l.Build.Output != "" {
+		return l.Build.Output, nil
+	}
+
+	return os.Getwd()
+}
+
+// copyOvhConfig copies the .ovhconfig file that 'owh runtime set' wrote in
+// src to the build output of l, if any.
+func copyOvhConfig(src string, l *config.Link) error {
+	if l.Build == nil || l.Build.Output == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(src, ovhconfig.Filename))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	dst := filepath.Join(l.Build.Output, ovhconfig.Filename)
+	if err := os.WriteFile(dst, data, 0644); err != nil {
+		return xerrors.Errorf("failed to copy %s to %s: %w", ovhconfig.Filename, l.Build.Output, err)
+	}
+
+	return nil
+}
+
+// printPlan renders the changes of plan, one line per path, followed by a
+// summary.
+func printPlan(v *view.View, plan *remote.Plan) {
+	for _, dir := range plan.Rmdir {
+		v.Printf("%s %s/\n", cmdutil.Color(lipgloss.Color("1")).Render("-"), dir)
+	}
+
+	for _, file := range plan.Delete {
+		v.Printf("%s %s (%s)\n", cmdutil.Color(lipgloss.Color("1")).Render("-"), file.Path, unit.FormatBytes(file.Size))
+	}
+
+	for _, dir := range plan.Mkdir {
+		v.Printf("%s %s/\n", cmdutil.Color(lipgloss.Color("2")).Render("+"), dir)
+	}
+
+	for _, file := range plan.Add {
+		v.Printf("%s %s (%s)\n", cmdutil.Color(lipgloss.Color("2")).Render("+"), file.Path, unit.FormatBytes(file.Size))
+	}
+
+	for _, file := range plan.Modify {
+		v.Printf("%s %s (%s)\n", cmdutil.Color(lipgloss.Color("3")).Render("~"), file.Path, unit.FormatBytes(file.Size))
+	}
+
+	if plan.IsEmpty() {
+		v.Printf("No changes, %d file(s) up to date\n", plan.Unchanged)
+		return
+	}
+
+	v.Printf(
+		"\n%d to add, %d to modify, %d to delete, %d unchanged\n",
+		len(plan.Add),
+		len(plan.Modify),
+		len(plan.Delete),
+		plan.Unchanged,
+	)
+	v.Printf(
+		"%d directories to create, %d to remove\n",
+		len(plan.Mkdir),
+		len(plan.Rmdir),
+	)
+	v.Printf(
+		"%s to upload, %s to delete\n",
+		unit.FormatBytes(plan.UploadBytes),
+		unit.FormatBytes(plan.DeleteBytes),
+	)
+}
+
+// printEnvPlan renders the changes to the environment variables a deployment
+// would make.
+func printEnvPlan(v *view.View, changes *flow.EnvChanges) {
+	if changes.IsEmpty() {
+		v.Println("Environment variables up to date")
+		return
+	}
+
+	v.Println("Environment variables:")
+	printEnvChanges(v, changes)
+}
+
+func printShadowed(paths []string) {
+	for _, path := range paths {
+		fmt.Printf("Warning: %s not uploaded, it would overwrite a preserved remote path\n", cmdutil.Highlight(path))
+	}
+}
@@ -0,0 +1,47 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.mlcdf.fr/owh/internal/config"
+)
+
+func TestCopyOvhConfig(t *testing.T) {
+	src := t.TempDir()
+	output := t.TempDir()
+	l := &config.Link{Build: &config.Build{Command: "make", Output: output}}
+
+	// nothing to copy
+	if err := copyOvhConfig(src, l); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(output, ".ovhconfig")); !os.IsNotExist(err) {
+		t.Errorf("want no .ovhconfig in the output, got %v", err)
+	}
+
+	// the file of the source directory replaces a stale one
+	if err := os.WriteFile(filepath.Join(output, ".ovhconfig"), []byte("app.engine.version=7.4\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "app.engine.version=8.2\n"
+	if err := os.WriteFile(filepath.Join(src, ".ovhconfig"), []byte(want), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := copyOvhConfig(src, l); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(output, ".ovhconfig"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
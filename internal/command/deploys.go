@@ -0,0 +1,154 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"go.mlcdf.fr/owh/internal/cmdutil"
+	"go.mlcdf.fr/owh/internal/flow"
+	"go.mlcdf.fr/owh/internal/remote"
+	"go.mlcdf.fr/owh/internal/unit"
+	"go.mlcdf.fr/owh/internal/view"
+	"golang.org/x/xerrors"
+)
+
+const deploymentDateFormat = "2006-01-02 15:04:05"
+
+type DeploysCommand struct {
+	App
+}
+
+func (c *DeploysCommand) Help() string {
+	helpText := `
+Usage: owh deploys [RELEASE]
+
+  Lists the deployments of the linked website, most recent first.
+  With a release, shows the details and the changed files of its deployment.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *DeploysCommand) Synopsis() string {
+	return "List the deployment history"
+}
+
+func (c *DeploysCommand) Run(args []string) int {
+	flags := flag.NewFlagSet("deploys", flag.ExitOnError)
+
+	if err := flags.Parse(args); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	link, err := c.EnsureLink()
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	client, err := c.LoggedClient()
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	conn, err := flow.NewSSHClient(client, c.Config, c.View, c.IsInteractive, link.Hosting)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	history, err := conn.History(link.CanonicalDomain)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if id := flags.Arg(0); id != "" {
+		for _, deployment := range history {
+			if deployment.Release == id {
+				printDeployment(c.View, &deployment)
+				return 0
+			}
+		}
+		return c.View.PrintErr(xerrors.Errorf("no deployment of release %s", id))
+	}
+
+	if len(history) == 0 {
+		return 0
+	}
+
+	tables := make([][]string, 0)
+
+	for i := len(history) - 1; i >= 0; i-- {
+		deployment := history[i]
+
+		row := []string{
+			deployment.Release,
+			deployment.Date.Local().Format(deploymentDateFormat),
+			deployment.User,
+			describeCommit(&deployment),
+			fmt.Sprintf("+%d ~%d -%d", len(deployment.Added), len(deployment.Modified), len(deployment.Removed)),
+			deployment.Duration.Round(time.Second).String(),
+		}
+		tables = append(tables, row)
+	}
+
+	err = c.View.Table("", tables, "Release", "Date", "User", "Commit", "Changes", "Duration")
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	return 0
+}
+
+// describeCommit returns the short commit hash of the deployment, followed by
+// its branch and a * when the working tree had uncommitted changes.
+func describeCommit(d *remote.Deployment) string {
+	if d.Commit == "" {
+		return "-"
+	}
+
+	out := d.Commit
+	if len(out) > 7 {
+		out = out[:7]
+	}
+
+	if d.Branch != "" {
+		out += " (" + d.Branch + ")"
+	}
+
+	if d.Dirty {
+		out += "*"
+	}
+
+	return out
+}
+
+func printDeployment(v *view.View, d *remote.Deployment) {
+	v.VerticalTable("Release "+d.Release, []view.LabelValue{
+		{Label: "Date", Value: d.Date.Local().Format(deploymentDateFormat)},
+		{Label: "User", Value: d.User},
+		{Label: "Commit", Value: describeCommit(d)},
+		{Label: "Duration", Value: d.Duration.Round(time.Millisecond).String()},
+		{Label: "Uploaded", Value: fmt.Sprintf("%d file(s), %s", d.Uploaded, unit.FormatBytes(d.Bytes))},
+		{Label: "Skipped", Value: fmt.Sprintf("%d file(s)", d.Skipped)},
+		{Label: "Deleted", Value: fmt.Sprintf("%d path(s)", d.Deleted)},
+	})
+
+	if len(d.Added)+len(d.Modified)+len(d.Removed) == 0 {
+		return
+	}
+
+	v.Println()
+
+	for _, path := range d.Removed {
+		v.Printf("%s %s\n", cmdutil.Color(lipgloss.Color("1")).Render("-"), path)
+	}
+
+	for _, path := range d.Added {
+		v.Printf("%s %s\n", cmdutil.Color(lipgloss.Color("2")).Render("+"), path)
+	}
+
+	for _, path := range d.Modified {
+		v.Printf("%s %s\n", cmdutil.Color(lipgloss.Color("3")).Render("~"), path)
+	}
+}
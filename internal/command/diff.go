@@ -0,0 +1,250 @@
+package command
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pmezard/go-difflib/difflib"
+	"go.mlcdf.fr/owh/internal/cmdutil"
+	"go.mlcdf.fr/owh/internal/flow"
+	"go.mlcdf.fr/owh/internal/ignore"
+	"go.mlcdf.fr/owh/internal/remote"
+	"go.mlcdf.fr/owh/internal/view"
+)
+
+// maxDiffSize is the size above which the content of files isn't compared.
+const maxDiffSize = 1 << 20
+
+// Exit codes of 'owh diff', kept apart so that CI can tell drift from a
+// failure to compare.
+const (
+	diffExitDrift = 1
+	diffExitError = 2
+)
+
+type DiffCommand struct {
+	App
+}
+
+func (c *DiffCommand) Help() string {
+	helpText := `
+Usage: owh diff [options] [DIR]
+
+  Compares DIR with the live website of the linked hosting.
+
+  Lists the files only present locally (+), only present on the hosting (-)
+  and the modified ones (~), followed by a unified diff of the modified text
+  files. DIR defaults to the directory 'owh deploy' uploads.
+
+  Exits with 0 when they're identical, 1 when they differ and 2 when they
+  couldn't be compared, so it can be used in CI to detect drift.
+
+Options:
+  --name-only   Only list the paths of the files that differ
+
+  The files are compared like 'owh deploy' does: the ignored and preserved
+  paths of .owh.json and .owhignore are left out.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *DiffCommand) Synopsis() string {
+	return "Compare a directory with the live website"
+}
+
+func (c *DiffCommand) Run(args []string) int {
+	var nameOnly bool
+	var opts remote.SyncOptions
+
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+
+	flags.BoolVar(&nameOnly, "name-only", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return c.fail(err)
+	}
+
+	l, err := c.EnsureLink()
+	if err != nil {
+		return c.fail(err)
+	}
+
+	directory, err := deployDirectory(flags.Arg(0), l)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	opts.Ignore, err = ignore.Load(directory, l.Ignore)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	opts.Preserve = ignore.New(l.Preserve...)
+
+	client, err := c.LoggedClient()
+	if err != nil {
+		return c.fail(err)
+	}
+
+	conn, err := flow.NewSSHClient(client, c.Config, c.View, c.IsInteractive, l.Hosting)
+	if err != nil {
+		return c.fail(err)
+	}
+	defer conn.Close()
+
+	plan, err := conn.Plan(directory, l.CanonicalDomain, opts)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	if plan.IsEmpty() {
+		c.View.Printf("No differences, %d file(s) identical\n", plan.Unchanged)
+		return 0
+	}
+
+	printDiffNames(c.View, plan)
+
+	if nameOnly {
+		return diffExitDrift
+	}
+
+	for _, file := range plan.Modify {
+		diff, err := diffFile(conn, filepath.Join(directory, file.Path), filepath.Join(l.CanonicalDomain, file.Path), file.Path)
+		if err != nil {
+			return c.fail(err)
+		}
+
+		c.View.Printf("\n%s", diff)
+	}
+
+	return diffExitDrift
+}
+
+// fail prints err and returns the exit code of a failed comparison.
+func (c *DiffCommand) fail(err error) int {
+	c.View.PrintErr(err)
+	return diffExitError
+}
+
+// diffFile returns the unified diff from the remote file to the local one,
+// or a note when they aren't text files.
+func diffFile(conn *remote.Client, localpath string, remotepath string, relpath string) (string, error) {
+	local, err := os.ReadFile(localpath)
+	if err != nil {
+		return "", err
+	}
+
+	if len(local) > maxDiffSize {
+		return cmdutil.Bold(relpath+" is too large to compare") + "\n", nil
+	}
+
+	live, err := conn.ReadFile(remotepath, maxDiffSize)
+	if errors.Is(err, remote.ErrFileTooLarge) {
+		return cmdutil.Bold(relpath+" is too large to compare") + "\n", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if isBinary(local) || isBinary(live) {
+		return cmdutil.Bold("Binary files "+relpath+" differ") + "\n", nil
+	}
+
+	diff, err := unifiedDiff(relpath, live, local)
+	if err != nil {
+		return "", err
+	}
+
+	return colorDiff(diff), nil
+}
+
+// unifiedDiff returns the unified diff from the live content to the local
+// one of the file at relpath.
+func unifiedDiff(relpath string, live []byte, local []byte) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(string(live)),
+		B:        splitLines(string(local)),
+		FromFile: "live/" + relpath,
+		ToFile:   "local/" + relpath,
+		Context:  3,
+	})
+}
+
+// splitLines splits content after each newline. Unlike difflib.SplitLines, a
+// trailing newline doesn't add an empty line.
+func splitLines(content string) []string {
+	lines := strings.SplitAfter(content, "\n")
+
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+
+	lines[len(lines)-1] += "\n"
+	return lines
+}
+
+// isBinary reports whether content isn't text. Like git, a NUL byte in the
+// first 8000 bytes makes it binary.
+func isBinary(content []byte) bool {
+	if len(content) > 8000 {
+		content = content[:8000]
+	}
+
+	return bytes.IndexByte(content, 0) >= 0
+}
+
+func colorDiff(diff string) string {
+	lines := strings.SplitAfter(diff, "\n")
+
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			lines[i] = cmdutil.Bold(strings.TrimSuffix(line, "\n")) + "\n"
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = cmdutil.Color(lipgloss.Color("6")).Render(strings.TrimSuffix(line, "\n")) + "\n"
+		case strings.HasPrefix(line, "+"):
+			lines[i] = cmdutil.Color(lipgloss.Color("2")).Render(strings.TrimSuffix(line, "\n")) + "\n"
+		case strings.HasPrefix(line, "-"):
+			lines[i] = cmdutil.Color(lipgloss.Color("1")).Render(strings.TrimSuffix(line, "\n")) + "\n"
+		}
+	}
+
+	return strings.Join(lines, "")
+}
+
+// printDiffNames lists the paths that differ between the live website and
+// the local directory.
+func printDiffNames(v *view.View, plan *remote.Plan) {
+	for _, dir := range plan.Rmdir {
+		v.Printf("%s %s/\n", cmdutil.Color(lipgloss.Color("1")).Render("-"), dir)
+	}
+
+	for _, file := range plan.Delete {
+		v.Printf("%s %s\n", cmdutil.Color(lipgloss.Color("1")).Render("-"), file.Path)
+	}
+
+	for _, dir := range plan.Mkdir {
+		v.Printf("%s %s/\n", cmdutil.Color(lipgloss.Color("2")).Render("+"), dir)
+	}
+
+	for _, file := range plan.Add {
+		v.Printf("%s %s\n", cmdutil.Color(lipgloss.Color("2")).Render("+"), file.Path)
+	}
+
+	for _, file := range plan.Modify {
+		v.Printf("%s %s\n", cmdutil.Color(lipgloss.Color("3")).Render("~"), file.Path)
+	}
+
+	v.Printf(
+		"\n%d added, %d modified, %d removed, %d identical\n",
+		len(plan.Add),
+		len(plan.Modify),
+		len(plan.Delete),
+		plan.Unchanged,
+	)
+}
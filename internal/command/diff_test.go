@@ -0,0 +1,39 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	t.Parallel()
+
+	diff, err := unifiedDiff("index.html", []byte("<h1>\nlive\n</h1>\n"), []byte("<h1>\nlocal\n</h1>\n"))
+	assert.NoError(t, err)
+
+	want := `--- live/index.html
++++ local/index.html
+@@ -1,3 +1,3 @@
+ <h1>
+-live
++local
+ </h1>
+`
+	assert.Equal(t, want, diff)
+}
+
+func TestIsBinary(t *testing.T) {
+	t.Parallel()
+
+	assert.False(t, isBinary([]byte("<h1>hello</h1>\n")))
+	assert.True(t, isBinary([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")))
+}
+
+func TestSplitLines(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"a\n", "b\n"}, splitLines("a\nb\n"))
+	assert.Equal(t, []string{"a\n", "b\n"}, splitLines("a\nb"))
+	assert.Equal(t, []string{}, splitLines(""))
+}
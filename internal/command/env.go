@@ -0,0 +1,30 @@
+package command
+
+import (
+	"strings"
+
+	"github.com/mitchellh/cli"
+)
+
+type EnvCommand struct {
+	App
+}
+
+func (c *EnvCommand) Help() string {
+	helpText := `
+Usage: owh env [--help] <command> [<args>]
+
+  Manages the environment variables of the websites of the hosting.
+
+  The values of the secret variables are masked, unless --reveal is passed.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *EnvCommand) Synopsis() string {
+	return "Manage environment variables"
+}
+
+func (c *EnvCommand) Run(args []string) int {
+	return cli.RunResultHelp
+}
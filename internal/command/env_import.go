@@ -0,0 +1,111 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"go.mlcdf.fr/owh/internal/cmdutil"
+	"go.mlcdf.fr/owh/internal/dotenv"
+	"go.mlcdf.fr/owh/internal/flow"
+	"go.mlcdf.fr/owh/internal/view"
+)
+
+type EnvImportCommand struct {
+	App
+}
+
+func (c *EnvImportCommand) Help() string {
+	helpText := `
+Usage: owh env import [<options>] [FILE]
+
+  Sets the environment variables of the hosting from a .env file. FILE
+  defaults to .env.
+
+  The variables already set keep their type, the new ones are created as
+  secrets unless --plain is passed.
+
+Options:
+  --hosting       service name (defaults to the linked one)
+  --plain         Create the new variables as non-secret
+  --prune         Remove the variables missing from FILE
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *EnvImportCommand) Synopsis() string {
+	return "Set environment variables from a .env file"
+}
+
+func (c *EnvImportCommand) Run(args []string) int {
+	var hosting string
+	var plain bool
+	var prune bool
+
+	flags := flag.NewFlagSet("env import", flag.ExitOnError)
+
+	flags.StringVar(&hosting, "hosting", "", "")
+	flags.BoolVar(&plain, "plain", false, "")
+	flags.BoolVar(&prune, "prune", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	file := flags.Arg(0)
+	if file == "" {
+		file = dotenv.Filename
+	}
+
+	vars, err := dotenv.Load(file)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if hosting == "" {
+		link, err := c.EnsureLink()
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		hosting = link.Hosting
+	}
+
+	client, err := c.LoggedClient()
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	changes, err := flow.ReconcileEnv(client, c.View, hosting, vars, !plain, prune)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if changes.IsEmpty() {
+		fmt.Println("Environment variables up to date")
+		return 0
+	}
+
+	printEnvChanges(c.View, changes)
+
+	fmt.Printf("Environment variables imported from %s\n", cmdutil.Highlight(file))
+
+	return 0
+}
+
+// printEnvChanges lists the keys of the variables changed, without their
+// values.
+func printEnvChanges(v *view.View, changes *flow.EnvChanges) {
+	for _, envVar := range changes.Delete {
+		v.Printf("%s %s\n", cmdutil.Color(lipgloss.Color("1")).Render("-"), envVar.Key)
+	}
+
+	for _, envVar := range changes.Create {
+		v.Printf("%s %s\n", cmdutil.Color(lipgloss.Color("2")).Render("+"), envVar.Key)
+	}
+
+	for _, envVar := range changes.Update {
+		v.Printf("%s %s\n", cmdutil.Color(lipgloss.Color("3")).Render("~"), envVar.Key)
+	}
+}
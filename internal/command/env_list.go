@@ -0,0 +1,91 @@
+package command
+
+import (
+	"flag"
+	"strings"
+
+	"go.mlcdf.fr/owh/internal/view"
+)
+
+type EnvListCommand struct {
+	App
+}
+
+func (c *EnvListCommand) Help() string {
+	helpText := `
+Usage: owh env list [<options>]
+
+  Lists the environment variables of the hosting.
+
+Options:
+  --hosting       service name (defaults to the linked one)
+  --reveal        Show the values of the secret variables
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *EnvListCommand) Synopsis() string {
+	return "List environment variables"
+}
+
+func (c *EnvListCommand) Run(args []string) int {
+	var hosting string
+	var reveal bool
+
+	flags := flag.NewFlagSet("env list", flag.ExitOnError)
+
+	flags.StringVar(&hosting, "hosting", "", "")
+	flags.BoolVar(&reveal, "reveal", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if hosting == "" {
+		link, err := c.EnsureLink()
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		hosting = link.Hosting
+	}
+
+	client, err := c.LoggedClient()
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	vars, err := client.EnvVars(hosting)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if len(vars) == 0 {
+		c.View.Println("No environment variable found")
+		return 0
+	}
+
+	tables := make([][]string, 0)
+
+	for _, v := range vars {
+		value := v.Value
+		if v.IsSecret() {
+			value = view.Mask(value, reveal)
+		}
+
+		row := []string{
+			v.Key,
+			value,
+			v.Type,
+			v.Status,
+		}
+		tables = append(tables, row)
+	}
+
+	err = c.View.Table("", tables, "Key", "Value", "Type", "Status")
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	return 0
+}
@@ -0,0 +1,123 @@
+package command
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ovh/go-ovh/ovh"
+	"go.mlcdf.fr/owh/internal/api"
+	"go.mlcdf.fr/owh/internal/cmdutil"
+	"go.mlcdf.fr/owh/internal/dotenv"
+	"go.mlcdf.fr/owh/internal/flow"
+	"go.mlcdf.fr/owh/internal/view"
+)
+
+type EnvSetCommand struct {
+	App
+}
+
+func (c *EnvSetCommand) Help() string {
+	helpText := `
+Usage: owh env set [<options>] KEY=VALUE...
+
+  Sets environment variables of the hosting. The variables already set keep
+  their type, unless --secret is passed.
+
+Options:
+  --hosting       service name (defaults to the linked one)
+  --secret        Store the values as secrets, masked when listed
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *EnvSetCommand) Synopsis() string {
+	return "Set environment variables"
+}
+
+func (c *EnvSetCommand) Run(args []string) int {
+	var hosting string
+	var secret bool
+
+	flags := flag.NewFlagSet("env set", flag.ExitOnError)
+
+	flags.StringVar(&hosting, "hosting", "", "")
+	flags.BoolVar(&secret, "secret", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if flags.NArg() == 0 {
+		fmt.Println("missing positional argument KEY=VALUE")
+		return 1
+	}
+
+	vars := make([]dotenv.Var, 0, flags.NArg())
+
+	for _, arg := range flags.Args() {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || !dotenv.ValidKey(key) {
+			fmt.Printf("invalid argument %q, expected KEY=VALUE\n", arg)
+			return 1
+		}
+
+		vars = append(vars, dotenv.Var{Key: key, Value: value})
+	}
+
+	if hosting == "" {
+		link, err := c.EnsureLink()
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		hosting = link.Hosting
+	}
+
+	client, err := c.LoggedClient()
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	for _, v := range vars {
+		if err := setEnvVar(client, c.View, hosting, v, secret); err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		fmt.Printf("%s set\n", cmdutil.Highlight(v.Key))
+	}
+
+	return 0
+}
+
+// setEnvVar creates the variable, or updates it when it's already set.
+func setEnvVar(client *api.Client, v *view.View, hosting string, envVar dotenv.Var, secret bool) error {
+	t := api.EnvVarString
+	if secret {
+		t = api.EnvVarPassword
+	}
+
+	existing, err := client.GetEnvVar(hosting, envVar.Key)
+
+	var e *ovh.APIError
+	if errors.As(err, &e) && e.Code == http.StatusNotFound {
+		id, err := client.CreateEnvVar(hosting, &api.EnvVar{Key: envVar.Key, Type: t, Value: envVar.Value})
+		if err != nil {
+			return err
+		}
+
+		return flow.WaitTaskDone(client, v, hosting, id, fmt.Sprintf("Setting %s", envVar.Key))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if !secret {
+		t = existing.Type
+	}
+
+	return client.UpdateEnvVar(hosting, &api.EnvVar{Key: envVar.Key, Type: t, Value: envVar.Value})
+}
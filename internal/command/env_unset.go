@@ -0,0 +1,76 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"go.mlcdf.fr/owh/internal/cmdutil"
+	"go.mlcdf.fr/owh/internal/flow"
+)
+
+type EnvUnsetCommand struct {
+	App
+}
+
+func (c *EnvUnsetCommand) Help() string {
+	helpText := `
+Usage: owh env unset [<options>] KEY...
+
+  Removes environment variables of the hosting.
+
+Options:
+  --hosting       service name (defaults to the linked one)
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *EnvUnsetCommand) Synopsis() string {
+	return "Remove environment variables"
+}
+
+func (c *EnvUnsetCommand) Run(args []string) int {
+	var hosting string
+
+	flags := flag.NewFlagSet("env unset", flag.ExitOnError)
+
+	flags.StringVar(&hosting, "hosting", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if flags.NArg() == 0 {
+		fmt.Println("missing positional argument KEY")
+		return 1
+	}
+
+	if hosting == "" {
+		link, err := c.EnsureLink()
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		hosting = link.Hosting
+	}
+
+	client, err := c.LoggedClient()
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	for _, key := range flags.Args() {
+		id, err := client.DeleteEnvVar(hosting, key)
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		if err := flow.WaitTaskDone(client, c.View, hosting, id, fmt.Sprintf("Unsetting %s", key)); err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		fmt.Printf("%s unset\n", cmdutil.Highlight(key))
+	}
+
+	return 0
+}
@@ -0,0 +1,109 @@
+package command
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"go.mlcdf.fr/owh/internal/cmdutil"
+	"go.mlcdf.fr/owh/internal/flow"
+	"go.mlcdf.fr/owh/internal/remote"
+	"golang.org/x/xerrors"
+)
+
+type ExecCommand struct {
+	App
+}
+
+func (c *ExecCommand) Help() string {
+	helpText := `
+Usage: owh exec [<options>] -- COMMAND
+
+  Runs COMMAND on the linked hosting and exits with its exit code.
+
+  The output of the command is streamed as it comes and the standard input
+  of owh is sent to it.
+
+Options:
+  --dir           directory to run the command from, relative to the home
+                  (defaults to the home)
+  --env           KEY=VALUE environment variable to set (can be repeated)
+  --timeout       kill the command after this duration (e.g. 30s, 5m)
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ExecCommand) Synopsis() string {
+	return "Run a command on the linked hosting"
+}
+
+func (c *ExecCommand) Run(args []string) int {
+	var dir string
+	var env cmdutil.StringSlice
+	var timeout time.Duration
+
+	flags := flag.NewFlagSet("exec", flag.ExitOnError)
+
+	flags.StringVar(&dir, "dir", "", "")
+	flags.Var(&env, "env", "")
+	flags.DurationVar(&timeout, "timeout", 0, "")
+
+	if err := flags.Parse(args); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	command := strings.Join(flags.Args(), " ")
+	if command == "" {
+		return c.View.PrintErr(xerrors.New("missing command, usage: owh exec -- COMMAND"))
+	}
+
+	vars := make(map[string]string, len(env))
+	for _, e := range env {
+		key, value, ok := strings.Cut(e, "=")
+		if !ok || key == "" {
+			return c.View.PrintErr(xerrors.Errorf("invalid environment variable %s, expected KEY=VALUE", e))
+		}
+		vars[key] = value
+	}
+
+	link, err := c.EnsureLink()
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	client, err := c.LoggedClient()
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	conn, err := flow.NewSSHClient(client, c.Config, c.View, c.IsInteractive, link.Hosting)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+	defer conn.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	code, err := conn.Exec(ctx, command, remote.ExecOptions{
+		Dir:    dir,
+		Env:    vars,
+		Stdin:  os.Stdin,
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	})
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	return code
+}
@@ -29,6 +29,10 @@ func (c *LoginCommand) Synopsis() string {
 }
 
 func (c *LoginCommand) Run(args []string) int {
+	if err := c.Config.LoadSecrets(); err != nil {
+		return c.View.PrintErr(err)
+	}
+
 	if c.Config.ConsumerKey != "" && c.IsInteractive {
 		var shouldContinue bool
 
@@ -64,6 +68,7 @@ func (c *LoginCommand) Run(args []string) int {
 	ckReq := client.NewCkRequest()
 	ckReq.AddRules(ovh.ReadOnly, "/me")
 	ckReq.AddRecursiveRules(ovh.ReadWrite, "/hosting/web")
+	ckReq.AddRecursiveRules(ovh.ReadWrite, "/me/document")
 
 	response, err := ckReq.Do()
 	if err != nil {
@@ -0,0 +1,115 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"go.mlcdf.fr/owh/internal/cmdutil"
+	"go.mlcdf.fr/owh/internal/config"
+	"go.mlcdf.fr/owh/internal/flow"
+	"go.mlcdf.fr/owh/internal/ignore"
+	"go.mlcdf.fr/owh/internal/remote"
+)
+
+type PullCommand struct {
+	App
+}
+
+func (c *PullCommand) Help() string {
+	helpText := `
+Usage: owh pull [options] [DIR]
+
+  Downloads the linked website from OVHcloud Web Hosting to DIR.
+
+  Only the files new or modified on the hosting are downloaded. DIR defaults
+  to the directory 'owh deploy' uploads.
+
+Options:
+  --hosting       service name (defaults to the linked one)
+  --path          folder to download, relative to the home of the hosting
+                  (defaults to the canonical domain of the link)
+  --delete        Remove the local files missing on the hosting
+  --ignore        Pattern of paths neither downloaded nor deleted (repeatable)
+  --concurrency   Number of files downloaded in parallel (default: 8)
+
+  The patterns of the .owhignore file of DIR and of the "ignore" list of
+  .owh.json apply, like for 'owh deploy'.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *PullCommand) Synopsis() string {
+	return "Download websites to a directory"
+}
+
+func (c *PullCommand) Run(args []string) int {
+	var hosting string
+	var path string
+	var patterns cmdutil.StringSlice
+	var opts remote.PullOptions
+
+	flags := flag.NewFlagSet("pull", flag.ExitOnError)
+
+	flags.StringVar(&hosting, "hosting", "", "")
+	flags.StringVar(&path, "path", "", "")
+	flags.BoolVar(&opts.Delete, "delete", false, "")
+	flags.Var(&patterns, "ignore", "")
+	flags.IntVar(&opts.Concurrency, "concurrency", remote.DefaultConcurrency, "")
+
+	if err := flags.Parse(args); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	l := &config.Link{}
+
+	if hosting == "" || path == "" {
+		var err error
+
+		l, err = c.EnsureLink()
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+	}
+
+	if hosting == "" {
+		hosting = l.Hosting
+	}
+
+	if path == "" {
+		path = l.CanonicalDomain
+	}
+
+	directory, err := deployDirectory(flags.Arg(0), l)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	opts.Ignore, err = ignore.Load(directory, append(l.Ignore, patterns...))
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	client, err := c.LoggedClient()
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	conn, err := flow.NewSSHClient(client, c.Config, c.View, c.IsInteractive, hosting)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+	defer conn.Close()
+
+	fmt.Printf("Pulling ./%s to %s\n", cmdutil.Highlight(path), directory)
+
+	stats, err := conn.Pull(path, directory, opts)
+	if err != nil {
+		fmt.Printf("failed to download files: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Files downloaded to %s (%s)\n", cmdutil.Highlight(directory), stats)
+
+	return 0
+}
@@ -0,0 +1,105 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"go.mlcdf.fr/owh/internal/cmdutil"
+	"go.mlcdf.fr/owh/internal/flow"
+	"go.mlcdf.fr/owh/internal/remote"
+)
+
+type ReleasesCommand struct {
+	App
+}
+
+func (c *ReleasesCommand) Help() string {
+	helpText := `
+Usage: owh releases [options]
+
+  Lists the releases of the linked website. The current release is
+  highlighted.
+
+Options:
+  --prune     Remove old releases
+  --keep      Number of releases to keep when pruning (default: 5)
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ReleasesCommand) Synopsis() string {
+	return "List and prune releases"
+}
+
+func (c *ReleasesCommand) Run(args []string) int {
+	var prune bool
+	var keep int
+
+	flags := flag.NewFlagSet("releases", flag.ExitOnError)
+
+	flags.BoolVar(&prune, "prune", false, "")
+	flags.IntVar(&keep, "keep", remote.DefaultKeepReleases, "")
+
+	if err := flags.Parse(args); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	link, err := c.EnsureLink()
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	client, err := c.LoggedClient()
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	conn, err := flow.NewSSHClient(client, c.Config, c.View, c.IsInteractive, link.Hosting)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if prune {
+		removed, err := conn.PruneReleases(link.CanonicalDomain, keep)
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		for _, id := range removed {
+			fmt.Printf("Release %s removed\n", id)
+		}
+	}
+
+	releases, err := conn.Releases(link.CanonicalDomain)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if len(releases) == 0 {
+		return 0
+	}
+
+	tables := make([][]string, 0)
+
+	for _, release := range releases {
+		id := release.ID
+
+		if release.Current {
+			id = cmdutil.Special(id)
+		}
+
+		row := []string{
+			id,
+			release.Path,
+		}
+		tables = append(tables, row)
+	}
+
+	err = c.View.Table("", tables, "Release", "Path")
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	return 0
+}
@@ -7,9 +7,8 @@ import (
 
 	"github.com/AlecAivazis/survey/v2"
 	"go.mlcdf.fr/owh/internal/api"
-	"go.mlcdf.fr/owh/internal/config"
 	"go.mlcdf.fr/owh/internal/flow"
-	"go.mlcdf.fr/owh/internal/view"
+	"go.mlcdf.fr/owh/internal/remote"
 	"golang.org/x/exp/slices"
 	"golang.org/x/xerrors"
 )
@@ -105,7 +104,15 @@ func (c *RemoveCommand) Run(args []string) int {
 			}
 		}
 
-		err = nuke(client, c.Config, c.View, c.IsInteractive, hosting, &selectedDomain)
+		// connected once confirmed, since it may ask for credentials or
+		// create an SSH user
+		conn, err := flow.NewSSHClient(client, c.Config, c.View, c.IsInteractive, hosting)
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+		defer conn.Close()
+
+		err = nuke(client, conn, hosting, &selectedDomain)
 		if err != nil {
 			c.View.PrintErr(err)
 		}
@@ -133,10 +140,20 @@ func (c *RemoveCommand) Run(args []string) int {
 		selectedDomains = mightBeRelated
 	}
 
+	if len(selectedDomains) == 0 {
+		return 0
+	}
+
+	conn, err := flow.NewSSHClient(client, c.Config, c.View, c.IsInteractive, hosting)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+	defer conn.Close()
+
 	for _, domain := range selectedDomains {
 		d := mapDomains[domain]
 
-		err := nuke(client, c.Config, c.View, c.IsInteractive, hosting, &d)
+		err := nuke(client, conn, hosting, &d)
 		if err != nil {
 			c.View.PrintErr(err)
 		}
@@ -146,15 +163,20 @@ func (c *RemoveCommand) Run(args []string) int {
 	return 0
 }
 
-func nuke(client *api.Client, config *config.Config, view *view.View, isInteractive bool, hosting string, domain *api.AttachedDomain) error {
-	conn, err := flow.NewSSHClient(client, config, view, isInteractive, hosting)
+func nuke(client *api.Client, conn *remote.Client, hosting string, domain *api.AttachedDomain) error {
+	err := conn.ForceRemove(domain.Path)
 	if err != nil {
-		return err
+		return xerrors.Errorf("failed remove %s : %w", domain.Path, err)
 	}
 
-	err = conn.ForceRemove(domain.Path)
+	err = conn.RemoveReleases(domain.Path)
 	if err != nil {
-		return xerrors.Errorf("failed remove %s : %w", domain.Path, err)
+		return xerrors.Errorf("failed remove releases of %s : %w", domain.Path, err)
+	}
+
+	err = conn.ForceRemove(remote.HistoryPath(domain.Path))
+	if err != nil {
+		return xerrors.Errorf("failed remove deployment history of %s : %w", domain.Path, err)
 	}
 
 	_, err = client.DeleteDomain(hosting, domain.Domain)
@@ -0,0 +1,60 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"go.mlcdf.fr/owh/internal/cmdutil"
+	"go.mlcdf.fr/owh/internal/flow"
+)
+
+type RollbackCommand struct {
+	App
+}
+
+func (c *RollbackCommand) Help() string {
+	helpText := `
+Usage: owh rollback [RELEASE]
+
+  Switches the linked website back to a previous release.
+  Without argument, rolls back to the release preceding the current one.
+  Run 'owh releases' to list the available releases.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *RollbackCommand) Synopsis() string {
+	return "Switch back to a previous release"
+}
+
+func (c *RollbackCommand) Run(args []string) int {
+	flags := flag.NewFlagSet("rollback", flag.ExitOnError)
+
+	if err := flags.Parse(args); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	link, err := c.EnsureLink()
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	client, err := c.LoggedClient()
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	conn, err := flow.NewSSHClient(client, c.Config, c.View, c.IsInteractive, link.Hosting)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	release, err := conn.Rollback(link.CanonicalDomain, flags.Arg(0))
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	fmt.Printf("Release %s is live at ./%s\n", cmdutil.Highlight(release), cmdutil.Highlight(link.CanonicalDomain))
+	return 0
+}
@@ -0,0 +1,149 @@
+package command
+
+import (
+	"flag"
+	"strings"
+
+	"go.mlcdf.fr/owh/internal/api"
+	"golang.org/x/exp/slices"
+)
+
+type RuntimeCommand struct {
+	App
+}
+
+func (c *RuntimeCommand) Help() string {
+	helpText := `
+Usage: owh runtime [<command>] [<options>]
+
+  Manages the PHP runtime of the websites of the hosting, set by their
+  .ovhconfig file. Shows the runtime of each path of the hosting, with the
+  domains attached to it, when run without subcommand.
+
+Options:
+  --hosting       service name (defaults to the linked one)
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *RuntimeCommand) Synopsis() string {
+	return "Manage the PHP runtime"
+}
+
+func (c *RuntimeCommand) Run(args []string) int {
+	var hosting string
+
+	flags := flag.NewFlagSet("runtime", flag.ExitOnError)
+
+	flags.StringVar(&hosting, "hosting", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if hosting == "" {
+		link, err := c.EnsureLink()
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		hosting = link.Hosting
+	}
+
+	client, err := c.LoggedClient()
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	configs, err := client.OvhConfigs(hosting)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if len(configs) == 0 {
+		c.View.Println("No runtime found")
+		return 0
+	}
+
+	domains, err := client.Domains(hosting)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	attached := map[int64][]string{}
+	runtimes := map[int64][]string{}
+
+	for _, domain := range domains {
+		config := configForPath(configs, domain.Path)
+		if config == nil {
+			continue
+		}
+
+		attached[config.ID] = append(attached[config.ID], domain.Domain)
+
+		if domain.RuntimeID != 0 {
+			runtime, err := client.GetRuntime(hosting, domain.RuntimeID)
+			if err != nil {
+				return c.View.PrintErr(err)
+			}
+
+			if !slices.Contains(runtimes[config.ID], runtime.Type) {
+				runtimes[config.ID] = append(runtimes[config.ID], runtime.Type)
+			}
+		}
+	}
+
+	tables := make([][]string, 0)
+
+	for _, config := range configs {
+		path := config.Path
+		if path == "" {
+			path = "/"
+		}
+
+		row := []string{
+			path,
+			orDash(strings.Join(attached[config.ID], ", ")),
+			config.EngineName + " " + config.EngineVersion,
+			config.Environment,
+			config.HTTPFirewall,
+			config.Container,
+			orDash(strings.Join(runtimes[config.ID], ", ")),
+		}
+		tables = append(tables, row)
+	}
+
+	err = c.View.Table("", tables, "Path", "Domains", "Engine", "Environment", "Firewall", "Container", "Runtime")
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	return 0
+}
+
+// configForPath returns the runtime applying to path: the one of the
+// closest directory having one, the home being "". configs are sorted by
+// path.
+func configForPath(configs []api.OvhConfig, path string) *api.OvhConfig {
+	path = strings.Trim(path, "/")
+
+	var found *api.OvhConfig
+
+	for i := range configs {
+		p := strings.Trim(configs[i].Path, "/")
+
+		if p == "" || p == path || strings.HasPrefix(path, p+"/") {
+			found = &configs[i]
+		}
+	}
+
+	return found
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+
+	return value
+}
@@ -0,0 +1,210 @@
+package command
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.mlcdf.fr/owh/internal/api"
+	"go.mlcdf.fr/owh/internal/cmdutil"
+	"go.mlcdf.fr/owh/internal/flow"
+	"go.mlcdf.fr/owh/internal/ovhconfig"
+	"golang.org/x/xerrors"
+)
+
+type RuntimeSetCommand struct {
+	App
+}
+
+func (c *RuntimeSetCommand) Help() string {
+	helpText := `
+Usage: owh runtime set [<options>] [DIR]
+
+  Changes the runtime of the linked website.
+
+  Writes the .ovhconfig file of DIR, so that 'owh deploy' keeps shipping it,
+  and applies it to the live website. DIR defaults to the current directory;
+  when .owh.json defines a build "output", 'owh deploy' copies the file into
+  it after building. The settings without flag are kept.
+
+Options:
+  --php           PHP version, as listed by 'owh runtime versions'
+  --environment   production, which caches the PHP files, or development
+  --firewall      http firewall: none or security
+  --container     image the website runs in, such as stable64
+  --engine        php, or phpcgi for the legacy engine
+  --local         Only write the .ovhconfig file of DIR
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *RuntimeSetCommand) Synopsis() string {
+	return "Change the PHP runtime"
+}
+
+func (c *RuntimeSetCommand) Run(args []string) int {
+	var change ovhconfig.Config
+	var local bool
+
+	flags := flag.NewFlagSet("runtime set", flag.ExitOnError)
+
+	flags.StringVar(&change.EngineVersion, "php", "", "")
+	flags.StringVar(&change.Environment, "environment", "", "")
+	flags.StringVar(&change.HTTPFirewall, "firewall", "", "")
+	flags.StringVar(&change.Container, "container", "", "")
+	flags.StringVar(&change.Engine, "engine", "", "")
+	flags.BoolVar(&local, "local", false, "")
+
+	if err := flags.Parse(args); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	l, err := c.EnsureLink()
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	// the build output is regenerated on each deploy, which copies the file
+	// of the source directory into it
+	directory := flags.Arg(0)
+	if directory == "" {
+		directory, err = os.Getwd()
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+	}
+
+	path := filepath.Join(directory, ovhconfig.Filename)
+
+	var client *api.Client
+	var live *api.OvhConfig
+
+	if !local {
+		client, err = c.LoggedClient()
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		configs, err := client.OvhConfigs(l.Hosting)
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		live = configForPath(configs, l.CanonicalDomain)
+	}
+
+	config, err := ovhconfig.Load(path)
+	if errors.Is(err, os.ErrNotExist) {
+		config = ovhconfig.Default()
+
+		// start from the runtime of the live website
+		if live != nil {
+			config = &ovhconfig.Config{
+				Engine:        live.EngineName,
+				EngineVersion: live.EngineVersion,
+				Environment:   live.Environment,
+				HTTPFirewall:  live.HTTPFirewall,
+				Container:     live.Container,
+			}
+		}
+	} else if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	mergeOvhConfig(config, &change)
+
+	if err := config.Validate(); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if !local {
+		capabilities, err := client.OvhConfigCapabilities(l.Hosting)
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		if !supported(capabilities, config) {
+			return c.View.PrintErr(xerrors.Errorf(
+				"%s %s in container %s isn't available on this hosting, see: owh runtime versions",
+				config.Engine,
+				config.EngineVersion,
+				config.Container,
+			))
+		}
+	}
+
+	if err := config.Save(path); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	fmt.Printf("Runtime written to %s\n", cmdutil.Highlight(path))
+
+	if local {
+		return 0
+	}
+
+	// the runtime of the website can only be changed once it has its own
+	// .ovhconfig, which the next deployment uploads
+	if live == nil || strings.Trim(live.Path, "/") != strings.Trim(l.CanonicalDomain, "/") {
+		fmt.Println("Run 'owh deploy' to apply it.")
+		return 0
+	}
+
+	id, err := client.ChangeOvhConfig(l.Hosting, live.ID, &api.OvhConfigChange{
+		Container:     config.Container,
+		EngineName:    config.Engine,
+		EngineVersion: config.EngineVersion,
+		Environment:   config.Environment,
+		HTTPFirewall:  config.HTTPFirewall,
+	})
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	err = flow.WaitTaskDone(client, c.View, l.Hosting, id, fmt.Sprintf("Switching ./%s to %s %s", l.CanonicalDomain, config.Engine, config.EngineVersion))
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	fmt.Printf("Runtime of ./%s changed\n", cmdutil.Highlight(l.CanonicalDomain))
+
+	return 0
+}
+
+// mergeOvhConfig sets the non-empty values of change in config.
+func mergeOvhConfig(config *ovhconfig.Config, change *ovhconfig.Config) {
+	if change.Engine != "" {
+		config.Engine = change.Engine
+	}
+
+	if change.EngineVersion != "" {
+		config.EngineVersion = change.EngineVersion
+	}
+
+	if change.Environment != "" {
+		config.Environment = change.Environment
+	}
+
+	if change.HTTPFirewall != "" {
+		config.HTTPFirewall = change.HTTPFirewall
+	}
+
+	if change.Container != "" {
+		config.Container = change.Container
+	}
+}
+
+// supported reports whether the engine, version and container of config are
+// among the capabilities of the hosting.
+func supported(capabilities []api.OvhConfigCapability, config *ovhconfig.Config) bool {
+	for _, capability := range capabilities {
+		if capability.EngineName == config.Engine && capability.EngineVersion == config.EngineVersion && capability.Container == config.Container {
+			return true
+		}
+	}
+
+	return false
+}
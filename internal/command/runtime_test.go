@@ -0,0 +1,38 @@
+package command
+
+import (
+	"testing"
+
+	"go.mlcdf.fr/owh/internal/api"
+)
+
+func TestConfigForPath(t *testing.T) {
+	configs := []api.OvhConfig{
+		{ID: 1, Path: ""},
+		{ID: 2, Path: "example.com"},
+		{ID: 3, Path: "example.com/blog"},
+	}
+
+	testCases := []struct {
+		path string
+		want int64
+	}{
+		{path: "", want: 1},
+		{path: "www", want: 1},
+		{path: "example.com", want: 2},
+		{path: "example.com/", want: 2},
+		{path: "example.com/shop", want: 2},
+		{path: "example.com/blog/2023", want: 3},
+		{path: "example.community", want: 1},
+	}
+
+	for _, test := range testCases {
+		if got := configForPath(configs, test.path); got == nil || got.ID != test.want {
+			t.Errorf("configForPath(%q) = %v, expected the config %d", test.path, got, test.want)
+		}
+	}
+
+	if got := configForPath(configs[1:], "www"); got != nil {
+		t.Errorf("configForPath(%q) = %v, expected none", "www", got)
+	}
+}
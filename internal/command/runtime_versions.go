@@ -0,0 +1,82 @@
+package command
+
+import (
+	"flag"
+	"strings"
+)
+
+type RuntimeVersionsCommand struct {
+	App
+}
+
+func (c *RuntimeVersionsCommand) Help() string {
+	helpText := `
+Usage: owh runtime versions [<options>]
+
+  Lists the engines, versions and containers the hosting supports. The
+  default runtime is marked with a *.
+
+Options:
+  --hosting       service name (defaults to the linked one)
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *RuntimeVersionsCommand) Synopsis() string {
+	return "List the available runtimes"
+}
+
+func (c *RuntimeVersionsCommand) Run(args []string) int {
+	var hosting string
+
+	flags := flag.NewFlagSet("runtime versions", flag.ExitOnError)
+
+	flags.StringVar(&hosting, "hosting", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if hosting == "" {
+		link, err := c.EnsureLink()
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		hosting = link.Hosting
+	}
+
+	client, err := c.LoggedClient()
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	capabilities, err := client.OvhConfigCapabilities(hosting)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	tables := make([][]string, 0)
+
+	for _, capability := range capabilities {
+		version := capability.EngineVersion
+		if capability.Default {
+			version += " *"
+		}
+
+		row := []string{
+			capability.EngineName,
+			version,
+			capability.Container,
+			strings.ToLower(strings.ReplaceAll(capability.SupportStatus, "_", " ")),
+		}
+		tables = append(tables, row)
+	}
+
+	err = c.View.Table("", tables, "Engine", "Version", "Container", "Support")
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	return 0
+}
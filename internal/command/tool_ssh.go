@@ -1,10 +1,14 @@
 package command
 
 import (
+	"flag"
 	"fmt"
 	"os"
-	"os/exec"
 	"strings"
+
+	"go.mlcdf.fr/owh/internal/flow"
+	"go.mlcdf.fr/owh/internal/remote"
+	"golang.org/x/term"
 )
 
 type SSHCommand struct {
@@ -13,12 +17,12 @@ type SSHCommand struct {
 
 func (c *SSHCommand) Help() string {
 	helpText := `
-Usage: owh tool ssh
+Usage: owh tool ssh [-- COMMAND]
 
   Provides a quick way to connect to the linked hosting.
 
-  Since it relies on openssh-client and sshpass, you'll need to have both
-  installed.
+  Opens an interactive shell on the hosting, or runs COMMAND and exits with
+  its exit code.
 `
 	return strings.TrimSpace(helpText)
 }
@@ -27,7 +31,15 @@ func (c *SSHCommand) Synopsis() string {
 	return "Connect to the linked hosting"
 }
 
-func (c *SSHCommand) Run(_ []string) int {
+func (c *SSHCommand) Run(args []string) int {
+	flags := flag.NewFlagSet("ssh", flag.ExitOnError)
+
+	if err := flags.Parse(args); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	command := strings.Join(flags.Args(), " ")
+
 	link, err := c.EnsureLink()
 	if err != nil {
 		return c.View.PrintErr(err)
@@ -38,45 +50,54 @@ func (c *SSHCommand) Run(_ []string) int {
 		return c.View.PrintErr(err)
 	}
 
-	credentials, ok := c.Config.SFTPCredentials[link.Hosting]
-	if !ok {
-		c.View.Println("Couldn't find credentials for", link.Hosting)
-		return 1
-	}
-
-	hosting, err := client.GetHosting(link.Hosting)
+	conn, err := flow.NewSSHClient(client, c.Config, c.View, c.IsInteractive, link.Hosting)
 	if err != nil {
 		return c.View.PrintErr(err)
 	}
+	defer conn.Close()
 
-	// We relies on ssh & sshpass because I wan't able to get a nice UX while using /x/crypto/ssh.
-	// I was able to connect, but had issue with escape sequences (for example arrow up/down to navigate history, etc...)
+	stdin := int(os.Stdin.Fd())
+	stdout := int(os.Stdout.Fd())
 
-	dependencies := []string{"ssh", "sshpass"}
-	for _, dep := range dependencies {
-		_, err := exec.LookPath(dep)
+	// Like ssh, a command only gets a terminal when the output isn't piped
+	var terminal *remote.Terminal
+
+	if term.IsTerminal(stdin) && (command == "" || term.IsTerminal(stdout)) {
+		width, height, err := term.GetSize(stdout)
 		if err != nil {
-			fmt.Printf("You need to have %s installed\n", dep)
-			return 0
+			return c.View.PrintErr(err)
 		}
-	}
 
-	args := []string{
-		"-p", credentials.Password,
-		"ssh", hosting.ServiceManagementAccess.SSH.URL, "-t",
-		"-l", credentials.User,
-		"-p", fmt.Sprint(hosting.ServiceManagementAccess.SSH.Port),
-	}
+		resize, stop := watchResize(stdout)
+		defer stop()
 
-	cmd := exec.Command("sshpass", args...)
+		terminal = &remote.Terminal{
+			Term:   os.Getenv("TERM"),
+			Size:   remote.TerminalSize{Width: width, Height: height},
+			Resize: resize,
+		}
 
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
+		if terminal.Term == "" {
+			terminal.Term = "xterm-256color"
+		}
 
-	if err := cmd.Run(); err != nil {
+		// Keys, including escape sequences, are sent as is to the hosting
+		state, err := term.MakeRaw(stdin)
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+		defer func() {
+			_ = term.Restore(stdin, state)
+		}()
+	}
+
+	code, err := conn.Shell(command, terminal, os.Stdin, os.Stdout, os.Stderr)
+	if err != nil {
+		if terminal != nil {
+			fmt.Print("\r")
+		}
 		return c.View.PrintErr(err)
 	}
 
-	return 0
+	return code
 }
@@ -0,0 +1,49 @@
+//go:build !windows
+
+package command
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"go.mlcdf.fr/owh/internal/remote"
+	"golang.org/x/term"
+)
+
+// watchResize sends the size of the terminal fd each time it receives
+// SIGWINCH, until stop is called.
+func watchResize(fd int) (<-chan remote.TerminalSize, func()) {
+	sig := make(chan os.Signal, 1)
+	sizes := make(chan remote.TerminalSize, 1)
+	done := make(chan struct{})
+
+	signal.Notify(sig, syscall.SIGWINCH)
+
+	go func() {
+		defer close(sizes)
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-sig:
+				width, height, err := term.GetSize(fd)
+				if err != nil {
+					continue
+				}
+
+				select {
+				case sizes <- remote.TerminalSize{Width: width, Height: height}:
+				case <-done:
+					return
+				}
+			}
+		}
+	}()
+
+	return sizes, func() {
+		signal.Stop(sig)
+		close(done)
+	}
+}
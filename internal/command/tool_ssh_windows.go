@@ -0,0 +1,50 @@
+//go:build windows
+
+package command
+
+import (
+	"time"
+
+	"go.mlcdf.fr/owh/internal/remote"
+	"golang.org/x/term"
+)
+
+// watchResize sends the size of the terminal fd each time it changes, until
+// stop is called. Windows has no SIGWINCH, the size is polled.
+func watchResize(fd int) (<-chan remote.TerminalSize, func()) {
+	sizes := make(chan remote.TerminalSize, 1)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(sizes)
+
+		ticker := time.NewTicker(250 * time.Millisecond)
+		defer ticker.Stop()
+
+		lastWidth, lastHeight, _ := term.GetSize(fd)
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				width, height, err := term.GetSize(fd)
+				if err != nil || (width == lastWidth && height == lastHeight) {
+					continue
+				}
+
+				lastWidth, lastHeight = width, height
+
+				select {
+				case sizes <- remote.TerminalSize{Width: width, Height: height}:
+				case <-done:
+					return
+				}
+			}
+		}
+	}()
+
+	return sizes, func() {
+		close(done)
+	}
+}
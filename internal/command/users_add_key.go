@@ -0,0 +1,71 @@
+package command
+
+import (
+	"flag"
+	"strings"
+
+	"go.mlcdf.fr/owh/internal/flow"
+)
+
+type UsersAddKeyCommand struct {
+	App
+}
+
+func (c *UsersAddKeyCommand) Help() string {
+	helpText := `
+Usage: owh users add-key [options]
+
+  Installs a public key in the ~/.ssh/authorized_keys of the ssh user, using
+  the current password session. Deployments then authenticate with the key
+  and the password is removed from the config.
+
+  The key is held by ssh-agent when it's loaded there, otherwise the
+  passphrase of an encrypted key is asked (or read from the
+  OWH_SSH_KEY_PASSPHRASE environment variable).
+
+Options:
+  --hosting   Hosting of the ssh user (default: the linked one)
+  --key       Private key to use (default: ~/.ssh/id_ed25519, id_ecdsa or
+              id_rsa, else a new key is generated)
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *UsersAddKeyCommand) Synopsis() string {
+	return "Authenticate ssh users with a key"
+}
+
+func (c *UsersAddKeyCommand) Run(args []string) int {
+	var hosting string
+	var key string
+
+	flags := flag.NewFlagSet("add-key", flag.ExitOnError)
+
+	flags.StringVar(&hosting, "hosting", "", "")
+	flags.StringVar(&key, "key", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	if hosting == "" {
+		link, err := c.EnsureLink()
+		if err != nil {
+			return c.View.PrintErr(err)
+		}
+
+		hosting = link.Hosting
+	}
+
+	client, err := c.LoggedClient()
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	err = flow.InstallKey(client, c.Config, c.View, c.IsInteractive, hosting, key)
+	if err != nil {
+		return c.View.PrintErr(err)
+	}
+
+	return 0
+}
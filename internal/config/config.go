@@ -2,6 +2,7 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path"
@@ -17,20 +18,37 @@ const ENV_REGION = ENV_PREFIX + "REGION"
 const ENV_CONSUMER_KEY = ENV_PREFIX + "CONSUMER_KEY"
 const ENV_SSH_USER = ENV_PREFIX + "SSH_USER"
 const ENV_SSH_PASSWORD = ENV_PREFIX + "SSH_PASSWORD"
+const ENV_SSH_HOST_FINGERPRINT = ENV_PREFIX + "SSH_HOST_FINGERPRINT"
+const ENV_SSH_KEY = ENV_PREFIX + "SSH_KEY"
+const ENV_SSH_KEY_PASSPHRASE = ENV_PREFIX + "SSH_KEY_PASSPHRASE"
 
 type Factory func(isInteractive bool) (*Config, error)
 
 type Config struct {
 	location string `json:"-"`
 
+	// secrets is where the secrets are kept when they're not in config.json
+	secrets SecretStore `json:"-"`
+	// secretsLoaded tells whether the secrets were read from the store,
+	// which only happens once a command needs them, see LoadSecrets.
+	secretsLoaded bool `json:"-"`
+	// secretsErr is the error raised while reading the secrets.
+	secretsErr error `json:"-"`
+
 	Region          string                  `json:"region,omitempty"`
 	ConsumerKey     string                  `json:"consumer_key,omitempty"`
 	SFTPCredentials map[string]*Credentials `json:"ssh_passwords,omitempty"`
+	// SecretStore is the name of the store holding the consumer key and
+	// the ssh passwords. They are kept in this file when it's empty.
+	SecretStore string `json:"secret_store,omitempty"`
 }
 
 type Credentials struct {
 	User     string `json:"user"`
-	Password string `json:"password"`
+	Password string `json:"password,omitempty"`
+	// Key is the path of the private key to authenticate with, instead of
+	// the password.
+	Key string `json:"key,omitempty"`
 }
 
 var _ Factory = New
@@ -52,11 +70,160 @@ func New(isInteractive bool) (*Config, error) {
 		config.SFTPCredentials = map[string]*Credentials{}
 	}
 
+	// the secrets are read from the store they were saved to, the one of
+	// OWH_SECRET_STORE is only where MigrateSecrets moves them
+	config.secrets, err = NewSecretStore(config.SecretStore)
+	if err != nil {
+		return nil, err
+	}
+
 	fromEnv(config, isInteractive)
 	return config, nil
 }
 
+// LoadSecrets reads the consumer key and the ssh passwords from the secret
+// store, the first time it's called. They aren't read by New since the store
+// may run secret-tool or pass, which can prompt for a passphrase, for
+// commands which don't need them.
+func (config *Config) LoadSecrets() error {
+	if !config.secretsLoaded {
+		config.secretsLoaded = true
+		config.secretsErr = config.loadSecrets()
+	}
+
+	return config.secretsErr
+}
+
+// loadSecrets reads the secrets missing from config from the secret store.
+// The ones already set, such as the consumer key of OWH_CONSUMER_KEY, are
+// kept.
+func (config *Config) loadSecrets() error {
+	if config.secrets == nil {
+		return nil
+	}
+
+	if config.ConsumerKey == "" {
+		consumerKey, err := config.secrets.Get(consumerKeySecret)
+		if err != nil && !errors.Is(err, ErrSecretNotFound) {
+			return err
+		}
+		config.ConsumerKey = consumerKey
+	}
+
+	for hosting, credentials := range config.SFTPCredentials {
+		if credentials.Password != "" {
+			continue
+		}
+
+		password, err := config.secrets.Get(sshPasswordSecret(hosting))
+		if err != nil && !errors.Is(err, ErrSecretNotFound) {
+			return err
+		}
+		credentials.Password = password
+	}
+
+	return nil
+}
+
+// MigrateSecrets moves the secrets to the store called name and saves the
+// config. They are removed from the previous store once saved.
+func (config *Config) MigrateSecrets(name string) error {
+	if err := config.LoadSecrets(); err != nil {
+		return err
+	}
+
+	store, err := NewSecretStore(name)
+	if err != nil {
+		return err
+	}
+
+	if name == config.SecretStore || (name == StorePlaintext && config.SecretStore == "") {
+		return config.Save()
+	}
+
+	previous := config.secrets
+
+	config.secrets = store
+	config.SecretStore = name
+
+	if err := config.Save(); err != nil {
+		return err
+	}
+
+	if previous == nil {
+		return nil
+	}
+
+	for _, key := range config.secretKeys() {
+		if err := previous.Delete(key); err != nil && !errors.Is(err, ErrSecretNotFound) {
+			logging.Debugf("failed to delete %s from the previous secret store: %s", key, err)
+		}
+	}
+
+	return nil
+}
+
+func (config *Config) secretKeys() []string {
+	keys := []string{consumerKeySecret}
+
+	for hosting := range config.SFTPCredentials {
+		keys = append(keys, sshPasswordSecret(hosting))
+	}
+
+	return keys
+}
+
+// withoutSecrets writes the secrets to the secret store and returns a copy of
+// config without them, to be written to config.json.
+func (config *Config) withoutSecrets() (*Config, error) {
+	stripped := *config
+	stripped.ConsumerKey = ""
+	stripped.SFTPCredentials = make(map[string]*Credentials, len(config.SFTPCredentials))
+
+	secrets := map[string]string{consumerKeySecret: config.ConsumerKey}
+
+	for hosting, credentials := range config.SFTPCredentials {
+		c := *credentials
+		secrets[sshPasswordSecret(hosting)] = c.Password
+		c.Password = ""
+		stripped.SFTPCredentials[hosting] = &c
+	}
+
+	for key, value := range secrets {
+		var err error
+
+		if value == "" {
+			err = config.secrets.Delete(key)
+		} else {
+			err = config.secrets.Set(key, value)
+		}
+
+		if err != nil && !errors.Is(err, ErrSecretNotFound) {
+			return nil, err
+		}
+	}
+
+	return &stripped, nil
+}
+
+// KnownHostsFile returns the location of the known_hosts file managed by owh,
+// holding the keys of the hostings trusted on first use.
+func KnownHostsFile() (string, error) {
+	return xdg.ConfigFile("owh/known_hosts")
+}
+
+// KeyFile returns the location of the SSH key generated by owh, when the user
+// has none.
+func KeyFile() (string, error) {
+	return xdg.ConfigFile("owh/id_ed25519")
+}
+
 func (config *Config) IsValid() error {
+	if err := config.LoadSecrets(); err != nil {
+		fmt.Printf("Failed to read secrets from the %s store: %s\n", config.SecretStore, err)
+		return cmdutil.ErrSilent
+	}
+
 	if config.Region == "" || config.ConsumerKey == "" {
 		if ci := os.Getenv("CI"); ci != "" {
 			fmt.Printf("To use owh in automation, set the %s environment variable.\n", ENV_CONSUMER_KEY)
@@ -126,7 +293,20 @@ func (config *Config) Save() error {
 		return nil
 	}
 
-	return save(config)
+	if err := config.LoadSecrets(); err != nil {
+		return xerrors.Errorf("failed to read secrets, not overwriting them: %w", err)
+	}
+
+	if config.secrets == nil {
+		return save(config)
+	}
+
+	stripped, err := config.withoutSecrets()
+	if err != nil {
+		return err
+	}
+
+	return save(stripped)
 }
 
 func save[Options *Config | *Link](opts Options) error {
@@ -149,7 +329,7 @@ func save[Options *Config | *Link](opts Options) error {
 	var fh *os.File
 
 	if _, err := os.Stat(location); os.IsNotExist(err) {
-		if err := os.MkdirAll(path.Dir(location), 0600); err != nil {
+		if err := os.MkdirAll(path.Dir(location), 0700); err != nil {
 			return err
 		}
 
@@ -21,6 +21,55 @@ type Link struct {
 
 	Hosting         string `json:"hosting,omitempty"`
 	CanonicalDomain string `json:"canonical_domain,omitempty"`
+
+	// Ignore holds gitignore-style patterns of files not to deploy, on top
+	// of the ones of the .owhignore file.
+	Ignore []string `json:"ignore,omitempty"`
+
+	// Preserve holds gitignore-style patterns of remote paths never deleted
+	// nor overwritten by a deployment, such as user uploads.
+	Preserve []string `json:"preserve,omitempty"`
+
+	Build *Build `json:"build,omitempty"`
+	Hooks *Hooks `json:"hooks,omitempty"`
+
+	// Crons are the scheduled scripts of the hosting. When set, a
+	// deployment creates and updates the crons of the hosting to match them,
+	// and removes the other crons of the website directory.
+	Crons []Cron `json:"crons,omitempty"`
+}
+
+// Cron is a script run by the hosting at a given frequency.
+type Cron struct {
+	// Command is the path of the script, relative to the home of the
+	// hosting. It identifies the cron.
+	Command string `json:"command"`
+	// Frequency has the format of the first 5 fields of a crontab.
+	Frequency string `json:"frequency"`
+	// Language is the runtime of the script, such as php8.2 or node18.
+	Language    string `json:"language"`
+	Email       string `json:"email,omitempty"`
+	Description string `json:"description,omitempty"`
+	Disabled    bool   `json:"disabled,omitempty"`
+}
+
+// Hooks lists the commands run on the hosting by a deployment.
+type Hooks struct {
+	// PreDeploy commands run from the release directory once the files are
+	// uploaded, before the website is switched to it.
+	PreDeploy []string `json:"pre_deploy,omitempty"`
+	// PostDeploy commands run from the website directory once it's live.
+	PostDeploy []string `json:"post_deploy,omitempty"`
+}
+
+// Build describes how to build the website before deploying it.
+type Build struct {
+	// Command is run through the shell from the linked directory.
+	Command string `json:"command,omitempty"`
+	// Output is the directory deployed once built.
+	Output string `json:"output,omitempty"`
+	// Env holds environment variables added to the ones of owh.
+	Env map[string]string `json:"env,omitempty"`
 }
 
 type LinkFactory func(isInteractive bool) (*Link, error)
@@ -0,0 +1,322 @@
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/adrg/xdg"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/xerrors"
+)
+
+const ENV_SECRET_STORE = ENV_PREFIX + "SECRET_STORE"
+const ENV_SECRETS_PASSPHRASE = ENV_PREFIX + "SECRETS_PASSPHRASE"
+
+// Names of the secret stores.
+const (
+	StorePlaintext = "plaintext"
+	StoreKeyring   = "keyring"
+	StorePass      = "pass"
+	StoreFile      = "file"
+)
+
+var StoreNames = []string{StoreKeyring, StorePass, StoreFile, StorePlaintext}
+
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretStore keeps the secrets of the config (consumer key and ssh
+// passwords) out of config.json.
+type SecretStore interface {
+	Get(key string) (string, error)
+	Set(key string, value string) error
+	Delete(key string) error
+}
+
+// NewSecretStore returns the store called name, or nil for the plaintext
+// one, which keeps the secrets in config.json.
+func NewSecretStore(name string) (SecretStore, error) {
+	switch name {
+	case "", StorePlaintext:
+		return nil, nil
+	case StoreKeyring:
+		return &keyringStore{}, nil
+	case StorePass:
+		return &passStore{}, nil
+	case StoreFile:
+		location, err := xdg.ConfigFile("owh/secrets.enc")
+		if err != nil {
+			return nil, err
+		}
+		return &fileStore{location: location, passphrase: os.Getenv(ENV_SECRETS_PASSPHRASE)}, nil
+	default:
+		return nil, xerrors.Errorf("unknown secret store %s, valid ones are %s", name, strings.Join(StoreNames, ", "))
+	}
+}
+
+// DefaultSecretStore returns the most secure store available: the keyring,
+// then pass, then the encrypted file.
+func DefaultSecretStore() string {
+	if _, err := exec.LookPath("secret-tool"); err == nil {
+		return StoreKeyring
+	}
+
+	if _, err := exec.LookPath("pass"); err == nil {
+		return StorePass
+	}
+
+	return StoreFile
+}
+
+const consumerKeySecret = "consumer_key"
+
+func sshPasswordSecret(hosting string) string {
+	return "ssh_password/" + hosting
+}
+
+// keyringStore keeps the secrets in the Secret Service keyring (GNOME
+// Keyring, KWallet, KeePassXC...) through the D-Bus client of libsecret.
+type keyringStore struct{}
+
+func (s *keyringStore) Get(key string) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command("secret-tool", "lookup", "service", "owh", "key", key)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		// secret-tool exits with 1 without any message when the secret is
+		// missing. A missing D-Bus session or a locked keyring are reported
+		// on stderr, and must not look like a missing secret.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && strings.TrimSpace(stderr.String()) == "" {
+			return "", xerrors.Errorf("%w: %s", ErrSecretNotFound, key)
+		}
+		return "", xerrors.Errorf("failed to run secret-tool: %s: %w", strings.TrimSpace(stderr.String()), err)
+	}
+
+	// secret-tool adds a newline when writing to a terminal
+	return strings.TrimSuffix(stdout.String(), "\n"), nil
+}
+
+func (s *keyringStore) Set(key string, value string) error {
+	cmd := exec.Command("secret-tool", "store", "--label", "owh "+key, "service", "owh", "key", key)
+	cmd.Stdin = strings.NewReader(value)
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return xerrors.Errorf("failed to store %s in the keyring: %s: %w", key, output, err)
+	}
+
+	return nil
+}
+
+func (s *keyringStore) Delete(key string) error {
+	if output, err := exec.Command("secret-tool", "clear", "service", "owh", "key", key).CombinedOutput(); err != nil {
+		return xerrors.Errorf("failed to delete %s from the keyring: %s: %w", key, output, err)
+	}
+
+	return nil
+}
+
+// passStore keeps the secrets in the password store of pass, under owh/.
+type passStore struct{}
+
+func (s *passStore) Get(key string) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command("pass", "show", "owh/"+key)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if strings.Contains(stderr.String(), "not in the password store") {
+			return "", xerrors.Errorf("%w: %s", ErrSecretNotFound, key)
+		}
+		return "", xerrors.Errorf("failed to run pass: %s: %w", strings.TrimSpace(stderr.String()), err)
+	}
+
+	// pass keeps the secret on the first line
+	secret, _, _ := strings.Cut(stdout.String(), "\n")
+	return secret, nil
+}
+
+func (s *passStore) Set(key string, value string) error {
+	cmd := exec.Command("pass", "insert", "--multiline", "--force", "owh/"+key)
+	cmd.Stdin = strings.NewReader(value + "\n")
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return xerrors.Errorf("failed to store %s in pass: %s: %w", key, output, err)
+	}
+
+	return nil
+}
+
+func (s *passStore) Delete(key string) error {
+	if output, err := exec.Command("pass", "rm", "--force", "owh/"+key).CombinedOutput(); err != nil {
+		if strings.Contains(string(output), "not in the password store") {
+			return xerrors.Errorf("%w: %s", ErrSecretNotFound, key)
+		}
+		return xerrors.Errorf("failed to delete %s from pass: %s: %w", key, output, err)
+	}
+
+	return nil
+}
+
+// fileStore keeps the secrets in a file encrypted with AES-256-GCM, the key
+// being derived from a passphrase with scrypt.
+type fileStore struct {
+	location   string
+	passphrase string
+
+	secrets map[string]string
+}
+
+type encryptedFile struct {
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+const encryptedFileVersion = 1
+
+func (s *fileStore) Get(key string) (string, error) {
+	if err := s.load(); err != nil {
+		return "", err
+	}
+
+	secret, ok := s.secrets[key]
+	if !ok {
+		return "", xerrors.Errorf("%w: %s", ErrSecretNotFound, key)
+	}
+
+	return secret, nil
+}
+
+func (s *fileStore) Set(key string, value string) error {
+	if err := s.load(); err != nil {
+		return err
+	}
+
+	s.secrets[key] = value
+	return s.save()
+}
+
+func (s *fileStore) Delete(key string) error {
+	if err := s.load(); err != nil {
+		return err
+	}
+
+	delete(s.secrets, key)
+	return s.save()
+}
+
+func (s *fileStore) gcm(salt []byte) (cipher.AEAD, error) {
+	if s.passphrase == "" {
+		return nil, xerrors.Errorf("the %s environment variable is required to use the encrypted secret file", ENV_SECRETS_PASSPHRASE)
+	}
+
+	key, err := scrypt.Key([]byte(s.passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func (s *fileStore) load() error {
+	if s.secrets != nil {
+		return nil
+	}
+
+	b, err := os.ReadFile(s.location)
+	if err != nil {
+		if os.IsNotExist(err) {
+			s.secrets = map[string]string{}
+			return nil
+		}
+		return xerrors.Errorf("error reading %s: %w", s.location, err)
+	}
+
+	var file encryptedFile
+	if err := json.Unmarshal(b, &file); err != nil || file.Version != encryptedFileVersion {
+		return xerrors.Errorf("invalid secret file %s", s.location)
+	}
+
+	gcm, err := s.gcm(file.Salt)
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := gcm.Open(nil, file.Nonce, file.Data, nil)
+	if err != nil {
+		return xerrors.Errorf("failed to decrypt %s, is %s right?", s.location, ENV_SECRETS_PASSPHRASE)
+	}
+
+	secrets := map[string]string{}
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return xerrors.Errorf("invalid secret file %s: %w", s.location, err)
+	}
+
+	s.secrets = secrets
+	return nil
+}
+
+func (s *fileStore) save() error {
+	file := encryptedFile{
+		Version: encryptedFileVersion,
+		Salt:    make([]byte, 16),
+	}
+
+	if _, err := rand.Read(file.Salt); err != nil {
+		return err
+	}
+
+	gcm, err := s.gcm(file.Salt)
+	if err != nil {
+		return err
+	}
+
+	file.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return err
+	}
+
+	plaintext, err := json.Marshal(s.secrets)
+	if err != nil {
+		return err
+	}
+
+	file.Data = gcm.Seal(nil, file.Nonce, plaintext, nil)
+
+	b, err := json.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("failed to encode json: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.location), 0700); err != nil {
+		return err
+	}
+
+	// written aside then renamed, so a failure can't lose the secrets
+	tmp := s.location + ".tmp"
+	if err := os.WriteFile(tmp, b, 0600); err != nil {
+		return fmt.Errorf("failed to write to file %s: %w", tmp, err)
+	}
+
+	return os.Rename(tmp, s.location)
+}
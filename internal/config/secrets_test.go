@@ -0,0 +1,132 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adrg/xdg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStore(t *testing.T) {
+	t.Parallel()
+
+	location := filepath.Join(t.TempDir(), "owh", "secrets.enc")
+
+	store := &fileStore{location: location, passphrase: "correct horse"}
+	require.NoError(t, store.Set(consumerKeySecret, "ck"))
+	require.NoError(t, store.Set(sshPasswordSecret("example.cluster"), "hunter2"))
+	require.NoError(t, store.Delete(sshPasswordSecret("example.cluster")))
+
+	reopened := &fileStore{location: location, passphrase: "correct horse"}
+
+	secret, err := reopened.Get(consumerKeySecret)
+	require.NoError(t, err)
+	require.Equal(t, "ck", secret)
+
+	_, err = reopened.Get(sshPasswordSecret("example.cluster"))
+	require.True(t, errors.Is(err, ErrSecretNotFound))
+
+	wrong := &fileStore{location: location, passphrase: "battery staple"}
+	_, err = wrong.Get(consumerKeySecret)
+	require.Error(t, err)
+}
+
+type memoryStore map[string]string
+
+func (s memoryStore) Get(key string) (string, error) {
+	secret, ok := s[key]
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+	return secret, nil
+}
+
+func (s memoryStore) Set(key string, value string) error {
+	s[key] = value
+	return nil
+}
+
+func (s memoryStore) Delete(key string) error {
+	delete(s, key)
+	return nil
+}
+
+func TestWithoutSecrets(t *testing.T) {
+	t.Parallel()
+
+	store := memoryStore{}
+
+	config := &Config{
+		secrets:     store,
+		ConsumerKey: "ck",
+		SFTPCredentials: map[string]*Credentials{
+			"example.cluster": {User: "user", Password: "hunter2"},
+		},
+	}
+
+	stripped, err := config.withoutSecrets()
+	require.NoError(t, err)
+
+	require.Empty(t, stripped.ConsumerKey)
+	require.Empty(t, stripped.SFTPCredentials["example.cluster"].Password)
+	require.Equal(t, "user", stripped.SFTPCredentials["example.cluster"].User)
+	require.Equal(t, "hunter2", config.SFTPCredentials["example.cluster"].Password, "the config in use must keep its secrets")
+
+	require.Equal(t, memoryStore{consumerKeySecret: "ck", sshPasswordSecret("example.cluster"): "hunter2"}, store)
+
+	loaded := &Config{
+		secrets:         store,
+		SFTPCredentials: stripped.SFTPCredentials,
+	}
+	require.NoError(t, loaded.loadSecrets())
+	require.Equal(t, "ck", loaded.ConsumerKey)
+	require.Equal(t, "hunter2", loaded.SFTPCredentials["example.cluster"].Password)
+}
+
+func TestMigrateSecretsWithEnvStore(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(ENV_CONSUMER_KEY, "")
+	t.Setenv(ENV_SECRETS_PASSPHRASE, "correct horse")
+	// the store to migrate to, the secrets are still in config.json
+	t.Setenv(ENV_SECRET_STORE, StoreFile)
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+
+	location, err := xdg.ConfigFile("owh/config.json")
+	require.NoError(t, err)
+
+	plaintext := `{"region": "ovh-eu", "consumer_key": "ck", "ssh_passwords": {"example.cluster": {"user": "user", "password": "hunter2"}}}`
+	require.NoError(t, os.WriteFile(location, []byte(plaintext), 0600))
+
+	config, err := New(false)
+	require.NoError(t, err)
+	require.Equal(t, "ck", config.ConsumerKey)
+	require.Equal(t, "hunter2", config.SFTPCredentials["example.cluster"].Password)
+
+	require.NoError(t, config.MigrateSecrets(StoreFile))
+
+	b, err := os.ReadFile(location)
+	require.NoError(t, err)
+	require.NotContains(t, string(b), "hunter2")
+	require.NotContains(t, string(b), `"ck"`)
+
+	migrated, err := New(false)
+	require.NoError(t, err)
+	require.Equal(t, StoreFile, migrated.SecretStore)
+	require.Empty(t, migrated.ConsumerKey, "the secrets must only be read once needed")
+
+	require.NoError(t, migrated.LoadSecrets())
+	require.Equal(t, "ck", migrated.ConsumerKey)
+	require.Equal(t, "hunter2", migrated.SFTPCredentials["example.cluster"].Password)
+
+	// a store which can't be read doesn't prevent commands without secrets
+	t.Setenv(ENV_SECRETS_PASSPHRASE, "")
+
+	locked, err := New(false)
+	require.NoError(t, err)
+	require.Error(t, locked.LoadSecrets())
+	require.Error(t, locked.Save(), "secrets which couldn't be read must not be overwritten")
+}
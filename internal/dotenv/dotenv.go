@@ -0,0 +1,147 @@
+// Package dotenv reads .env files.
+package dotenv
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// Filename is the name of the file read by default.
+const Filename = ".env"
+
+var keyRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Var is a variable of a .env file.
+type Var struct {
+	Key   string
+	Value string
+}
+
+// Parse reads the variables of a .env file, in order. A key defined twice
+// keeps its last value, at the position of the first one.
+//
+// Lines are KEY=VALUE, optionally prefixed by "export". Values in single
+// quotes are taken as is, values in double quotes support the \n, \t, \"
+// and \\ escapes. Outside of quotes, a # preceded by a space starts a
+// comment. Blank lines and lines starting with # are skipped.
+func Parse(r io.Reader) ([]Var, error) {
+	var vars []Var
+	index := map[string]int{}
+
+	scanner := bufio.NewScanner(r)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		text = strings.TrimPrefix(text, "export ")
+
+		key, value, ok := strings.Cut(text, "=")
+		if !ok {
+			return nil, xerrors.Errorf("line %d: expected KEY=VALUE, got %q", line, text)
+		}
+
+		key = strings.TrimSpace(key)
+		if !ValidKey(key) {
+			return nil, xerrors.Errorf("line %d: invalid key %q", line, key)
+		}
+
+		value, err := parseValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, xerrors.Errorf("line %d: %w", line, err)
+		}
+
+		if i, ok := index[key]; ok {
+			vars[i].Value = value
+			continue
+		}
+
+		index[key] = len(vars)
+		vars = append(vars, Var{Key: key, Value: value})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return vars, nil
+}
+
+// ValidKey reports whether key is a valid variable name.
+func ValidKey(key string) bool {
+	return keyRe.MatchString(key)
+}
+
+// Load reads the .env file at path.
+func Load(path string) ([]Var, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	vars, err := Parse(f)
+	if err != nil {
+		return nil, xerrors.Errorf("error parsing %s: %w", path, err)
+	}
+
+	return vars, nil
+}
+
+func parseValue(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	switch quote := value[0]; quote {
+	case '\'':
+		end := strings.IndexByte(value[1:], '\'')
+		if end < 0 {
+			return "", xerrors.New("unterminated single quote")
+		}
+
+		return value[1 : end+1], nil
+	case '"':
+		var b strings.Builder
+
+		for i := 1; i < len(value); i++ {
+			switch c := value[i]; c {
+			case '"':
+				return b.String(), nil
+			case '\\':
+				i++
+				if i == len(value) {
+					return "", xerrors.New("unterminated double quote")
+				}
+
+				switch value[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case 't':
+					b.WriteByte('\t')
+				case 'r':
+					b.WriteByte('\r')
+				default:
+					b.WriteByte(value[i])
+				}
+			default:
+				b.WriteByte(c)
+			}
+		}
+
+		return "", xerrors.New("unterminated double quote")
+	}
+
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = value[:i]
+	}
+
+	return strings.TrimSpace(value), nil
+}
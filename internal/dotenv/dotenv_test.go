@@ -0,0 +1,63 @@
+package dotenv
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	content := `
+# database
+DB_HOST=db.example.com
+export DB_USER = admin
+DB_PASSWORD='p@ss #word'
+GREETING="hello\n\"world\""
+EMPTY=
+DEBUG=true # comment
+URL=https://example.com/#anchor
+DB_HOST=db2.example.com
+`
+
+	vars, err := Parse(strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Var{
+		{Key: "DB_HOST", Value: "db2.example.com"},
+		{Key: "DB_USER", Value: "admin"},
+		{Key: "DB_PASSWORD", Value: "p@ss #word"},
+		{Key: "GREETING", Value: "hello\n\"world\""},
+		{Key: "EMPTY", Value: ""},
+		{Key: "DEBUG", Value: "true"},
+		{Key: "URL", Value: "https://example.com/#anchor"},
+	}
+
+	if !reflect.DeepEqual(vars, expected) {
+		t.Errorf("Parse() = %q, expected %q", vars, expected)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+	}{
+		{name: "no equal sign", content: "KEY"},
+		{name: "invalid key", content: "MY-KEY=value"},
+		{name: "unterminated single quote", content: "KEY='value"},
+		{name: "unterminated double quote", content: `KEY="value`},
+		{name: "escape at the end", content: `KEY="value\`},
+	}
+
+	for _, test := range testCases {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Parse(strings.NewReader(test.content)); err == nil {
+				t.Errorf("Parse(%q) succeeded, expected an error", test.content)
+			}
+		})
+	}
+}
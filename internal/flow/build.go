@@ -0,0 +1,48 @@
+package flow
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"go.mlcdf.fr/owh/internal/cmdutil"
+	"go.mlcdf.fr/owh/internal/config"
+	"golang.org/x/xerrors"
+)
+
+// Build runs the build command of the link through the shell, with the
+// environment variables of the link added to the current ones. Its output is
+// streamed to stdout and stderr.
+func Build(build *config.Build, stdout io.Writer, stderr io.Writer) error {
+	var cmd *exec.Cmd
+
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", build.Command)
+	} else {
+		cmd = exec.Command("sh", "-c", build.Command)
+	}
+
+	cmd.Env = os.Environ()
+	for key, value := range build.Env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	fmt.Fprintf(stderr, "Building with %s\n", cmdutil.Highlight(build.Command))
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return xerrors.Errorf("build command exited with code %d", exitErr.ExitCode())
+		}
+		return xerrors.Errorf("failed to run build command: %w", err)
+	}
+
+	return nil
+}
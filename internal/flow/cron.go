@@ -0,0 +1,187 @@
+package flow
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"go.mlcdf.fr/owh/internal/api"
+	"go.mlcdf.fr/owh/internal/config"
+	"golang.org/x/xerrors"
+)
+
+// CronChanges lists the changes made to the crons of a hosting to match the
+// ones of a link.
+type CronChanges struct {
+	Create []api.Cron
+	Update []api.Cron
+	Delete []api.Cron
+}
+
+func (changes *CronChanges) IsEmpty() bool {
+	return len(changes.Create) == 0 && len(changes.Update) == 0 && len(changes.Delete) == 0
+}
+
+// PlanCrons returns the changes turning the current crons into the wanted
+// ones. They are matched on their command: the wanted crons missing from
+// current are created, the ones with different settings updated, and the
+// current crons not wanted deleted, like the duplicates.
+//
+// The crons belong to the whole hosting, so only the ones managed by the
+// link are deleted: the wanted ones and those whose command is under dir, the
+// directory of the website. The others, of other websites or added in the
+// control panel, are left alone.
+func PlanCrons(current []api.Cron, wanted []config.Cron, dir string) (*CronChanges, error) {
+	if err := ValidateCrons(wanted); err != nil {
+		return nil, err
+	}
+
+	changes := &CronChanges{}
+
+	matched := map[string]bool{}
+	for _, w := range wanted {
+		matched[w.Command] = true
+	}
+
+	managed := func(cron *api.Cron) bool {
+		return matched[cron.Command] || isUnder(cron.Command, dir)
+	}
+
+	byCommand := map[string]*api.Cron{}
+	for i := range current {
+		cron := &current[i]
+
+		if _, ok := byCommand[cron.Command]; ok {
+			if managed(cron) {
+				changes.Delete = append(changes.Delete, *cron)
+			}
+			continue
+		}
+
+		byCommand[cron.Command] = cron
+	}
+
+	for _, w := range wanted {
+		cron := CronFromConfig(w)
+
+		existing, ok := byCommand[w.Command]
+		if !ok {
+			changes.Create = append(changes.Create, cron)
+			continue
+		}
+
+		cron.ID = existing.ID
+		if cron != *existing {
+			changes.Update = append(changes.Update, cron)
+		}
+	}
+
+	for i := range current {
+		cron := &current[i]
+
+		if !matched[cron.Command] && byCommand[cron.Command] == cron && managed(cron) {
+			changes.Delete = append(changes.Delete, *cron)
+		}
+	}
+
+	return changes, nil
+}
+
+// isUnder reports whether the script of command, relative to the home of the
+// hosting, is in dir.
+func isUnder(command string, dir string) bool {
+	dir = strings.Trim(path.Clean(dir), "/")
+	if dir == "" || dir == "." {
+		return false
+	}
+
+	return strings.HasPrefix(path.Clean(strings.TrimPrefix(command, "/")), dir+"/")
+}
+
+// ValidateCrons checks the crons of .owh.json: each has a command, a
+// language and a valid frequency, and no command is listed twice.
+func ValidateCrons(crons []config.Cron) error {
+	seen := map[string]bool{}
+
+	for _, c := range crons {
+		if c.Command == "" {
+			return xerrors.New("cron without command in .owh.json")
+		}
+
+		if seen[c.Command] {
+			return xerrors.Errorf("cron %s is listed twice in .owh.json", c.Command)
+		}
+		seen[c.Command] = true
+
+		if c.Language == "" {
+			return xerrors.Errorf("cron %s of .owh.json has no language", c.Command)
+		}
+
+		if err := ValidateFrequency(c.Frequency); err != nil {
+			return xerrors.Errorf("cron %s of .owh.json: %w", c.Command, err)
+		}
+	}
+
+	return nil
+}
+
+// ValidateFrequency checks that frequency has the 5 fields of a crontab
+// schedule.
+func ValidateFrequency(frequency string) error {
+	if len(strings.Fields(frequency)) != 5 {
+		return fmt.Errorf("invalid frequency %q, expected 5 fields such as \"0 3 * * *\"", frequency)
+	}
+
+	return nil
+}
+
+// CronFromConfig returns the cron of the API described by c.
+func CronFromConfig(c config.Cron) api.Cron {
+	status := api.CronEnabled
+	if c.Disabled {
+		status = api.CronDisabled
+	}
+
+	return api.Cron{
+		Command:     c.Command,
+		Description: c.Description,
+		Email:       c.Email,
+		Frequency:   c.Frequency,
+		Language:    c.Language,
+		Status:      status,
+	}
+}
+
+// ReconcileCrons creates, updates and deletes the crons of the hosting so
+// that they match the wanted ones, see PlanCrons.
+func ReconcileCrons(client *api.Client, hosting string, wanted []config.Cron, dir string) (*CronChanges, error) {
+	current, err := client.Crons(hosting)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := PlanCrons(current, wanted, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, cron := range changes.Delete {
+		if err := client.DeleteCron(hosting, cron.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range changes.Update {
+		if err := client.UpdateCron(hosting, &changes.Update[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range changes.Create {
+		if err := client.CreateCron(hosting, &changes.Create[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return changes, nil
+}
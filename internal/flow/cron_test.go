@@ -0,0 +1,131 @@
+package flow
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mlcdf.fr/owh/internal/api"
+	"go.mlcdf.fr/owh/internal/config"
+)
+
+func TestPlanCrons(t *testing.T) {
+	current := []api.Cron{
+		{ID: 1, Command: "www/backup.php", Frequency: "0 3 * * *", Language: "php8.2", Status: api.CronEnabled},
+		{ID: 2, Command: "www/mail.php", Frequency: "0 * * * *", Language: "php8.1", Status: api.CronEnabled},
+		{ID: 3, Command: "www/old.php", Frequency: "0 1 * * *", Language: "php8.2", Status: api.CronEnabled},
+		{ID: 4, Command: "www/backup.php", Frequency: "0 4 * * *", Language: "php8.2", Status: api.CronEnabled},
+	}
+
+	wanted := []config.Cron{
+		{Command: "www/backup.php", Frequency: "0 3 * * *", Language: "php8.2"},
+		{Command: "www/mail.php", Frequency: "0 * * * *", Language: "php8.2", Disabled: true},
+		{Command: "www/new.php", Frequency: "30 2 * * 1", Language: "php8.2", Email: "ops@example.com"},
+	}
+
+	changes, err := PlanCrons(current, wanted, "www")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &CronChanges{
+		Create: []api.Cron{
+			{Command: "www/new.php", Frequency: "30 2 * * 1", Language: "php8.2", Email: "ops@example.com", Status: api.CronEnabled},
+		},
+		Update: []api.Cron{
+			{ID: 2, Command: "www/mail.php", Frequency: "0 * * * *", Language: "php8.2", Status: api.CronDisabled},
+		},
+		Delete: []api.Cron{current[3], current[2]},
+	}
+
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("PlanCrons() = %+v, expected %+v", changes, expected)
+	}
+}
+
+func TestPlanCronsUnchanged(t *testing.T) {
+	current := []api.Cron{
+		{ID: 1, Command: "www/backup.php", Frequency: "0 3 * * *", Language: "php8.2", Status: api.CronEnabled},
+	}
+
+	changes, err := PlanCrons(current, []config.Cron{{Command: "www/backup.php", Frequency: "0 3 * * *", Language: "php8.2"}}, "www")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !changes.IsEmpty() {
+		t.Errorf("PlanCrons() = %+v, expected no change", changes)
+	}
+}
+
+func TestPlanCronsOtherWebsites(t *testing.T) {
+	current := []api.Cron{
+		{ID: 1, Command: "www/backup.php", Frequency: "0 3 * * *", Language: "php8.2", Status: api.CronEnabled},
+		{ID: 2, Command: "blog/backup.php", Frequency: "0 3 * * *", Language: "php8.2", Status: api.CronEnabled},
+		{ID: 3, Command: "scripts/stats.sh", Frequency: "0 5 * * *", Language: "other", Status: api.CronEnabled},
+		{ID: 4, Command: "blog/backup.php", Frequency: "0 4 * * *", Language: "php8.2", Status: api.CronEnabled},
+		{ID: 5, Command: "www2/backup.php", Frequency: "0 3 * * *", Language: "php8.2", Status: api.CronEnabled},
+	}
+
+	changes, err := PlanCrons(current, nil, "www")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &CronChanges{Delete: []api.Cron{current[0]}}
+
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("PlanCrons() = %+v, expected %+v", changes, expected)
+	}
+}
+
+func TestPlanCronsDuplicate(t *testing.T) {
+	wanted := []config.Cron{
+		{Command: "www/backup.php", Frequency: "0 3 * * *", Language: "php8.2"},
+		{Command: "www/backup.php", Frequency: "0 4 * * *", Language: "php8.2"},
+	}
+
+	if _, err := PlanCrons(nil, wanted, "www"); err == nil {
+		t.Error("PlanCrons() succeeded, expected an error for the duplicated command")
+	}
+}
+
+func TestPlanCronsClearsEmail(t *testing.T) {
+	current := []api.Cron{
+		{ID: 1, Command: "www/backup.php", Frequency: "0 3 * * *", Language: "php8.2", Email: "ops@example.com", Status: api.CronEnabled},
+	}
+
+	changes, err := PlanCrons(current, []config.Cron{{Command: "www/backup.php", Frequency: "0 3 * * *", Language: "php8.2"}}, "www")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []api.Cron{{ID: 1, Command: "www/backup.php", Frequency: "0 3 * * *", Language: "php8.2", Status: api.CronEnabled}}
+
+	if !reflect.DeepEqual(changes.Update, expected) {
+		t.Errorf("PlanCrons() updates %+v, expected %+v", changes.Update, expected)
+	}
+}
+
+func TestValidateCrons(t *testing.T) {
+	testCases := []struct {
+		name  string
+		cron  config.Cron
+		valid bool
+	}{
+		{name: "valid", cron: config.Cron{Command: "www/cron.php", Frequency: "0 3 * * *", Language: "php8.2"}, valid: true},
+		{name: "no command", cron: config.Cron{Frequency: "0 3 * * *", Language: "php8.2"}},
+		{name: "no language", cron: config.Cron{Command: "www/cron.php", Frequency: "0 3 * * *"}},
+		{name: "no frequency", cron: config.Cron{Command: "www/cron.php", Language: "php8.2"}},
+		{name: "short frequency", cron: config.Cron{Command: "www/cron.php", Frequency: "0 3 * *", Language: "php8.2"}},
+	}
+
+	for _, test := range testCases {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			if err := ValidateCrons([]config.Cron{test.cron}); (err == nil) != test.valid {
+				t.Errorf("ValidateCrons() = %v, expected valid: %v", err, test.valid)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package flow
+
+import (
+	"fmt"
+
+	"go.mlcdf.fr/owh/internal/api"
+	"go.mlcdf.fr/owh/internal/dotenv"
+	"go.mlcdf.fr/owh/internal/view"
+)
+
+// EnvChanges lists the changes made to the environment variables of a
+// hosting to match a .env file.
+type EnvChanges struct {
+	Create []api.EnvVar
+	Update []api.EnvVar
+	Delete []api.EnvVar
+}
+
+func (changes *EnvChanges) IsEmpty() bool {
+	return len(changes.Create) == 0 && len(changes.Update) == 0 && len(changes.Delete) == 0
+}
+
+// PlanEnv returns the changes setting vars on top of the current variables.
+// The variables already set keep their type, the new ones are secrets when
+// secret is true. With prune, the current variables missing from vars are
+// deleted.
+func PlanEnv(current []api.EnvVar, vars []dotenv.Var, secret bool, prune bool) *EnvChanges {
+	changes := &EnvChanges{}
+
+	byKey := map[string]api.EnvVar{}
+	for _, v := range current {
+		byKey[v.Key] = v
+	}
+
+	wanted := map[string]bool{}
+
+	for _, v := range vars {
+		wanted[v.Key] = true
+
+		existing, ok := byKey[v.Key]
+		if !ok {
+			t := api.EnvVarString
+			if secret {
+				t = api.EnvVarPassword
+			}
+
+			changes.Create = append(changes.Create, api.EnvVar{Key: v.Key, Type: t, Value: v.Value})
+			continue
+		}
+
+		if existing.Value != v.Value {
+			changes.Update = append(changes.Update, api.EnvVar{Key: v.Key, Type: existing.Type, Value: v.Value})
+		}
+	}
+
+	if prune {
+		for _, v := range current {
+			if !wanted[v.Key] {
+				changes.Delete = append(changes.Delete, v)
+			}
+		}
+	}
+
+	return changes
+}
+
+// ReconcileEnv applies the changes of PlanEnv to the hosting, waiting for
+// each of them to be done.
+func ReconcileEnv(client *api.Client, v *view.View, hosting string, vars []dotenv.Var, secret bool, prune bool) (*EnvChanges, error) {
+	current, err := client.EnvVars(hosting)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := PlanEnv(current, vars, secret, prune)
+
+	for _, envVar := range changes.Delete {
+		id, err := client.DeleteEnvVar(hosting, envVar.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := WaitTaskDone(client, v, hosting, id, fmt.Sprintf("Unsetting %s", envVar.Key)); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range changes.Update {
+		if err := client.UpdateEnvVar(hosting, &changes.Update[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range changes.Create {
+		id, err := client.CreateEnvVar(hosting, &changes.Create[i])
+		if err != nil {
+			return nil, err
+		}
+
+		if err := WaitTaskDone(client, v, hosting, id, fmt.Sprintf("Setting %s", changes.Create[i].Key)); err != nil {
+			return nil, err
+		}
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,58 @@
+package flow
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mlcdf.fr/owh/internal/api"
+	"go.mlcdf.fr/owh/internal/dotenv"
+)
+
+func TestPlanEnv(t *testing.T) {
+	current := []api.EnvVar{
+		{Key: "APP_ENV", Type: api.EnvVarString, Value: "production"},
+		{Key: "DB_PASSWORD", Type: api.EnvVarPassword, Value: "old"},
+		{Key: "LEGACY", Type: api.EnvVarString, Value: "1"},
+	}
+
+	vars := []dotenv.Var{
+		{Key: "APP_ENV", Value: "production"},
+		{Key: "DB_PASSWORD", Value: "new"},
+		{Key: "API_TOKEN", Value: "secret"},
+	}
+
+	testCases := []struct {
+		name     string
+		secret   bool
+		prune    bool
+		expected *EnvChanges
+	}{
+		{
+			name: "plain",
+			expected: &EnvChanges{
+				Create: []api.EnvVar{{Key: "API_TOKEN", Type: api.EnvVarString, Value: "secret"}},
+				Update: []api.EnvVar{{Key: "DB_PASSWORD", Type: api.EnvVarPassword, Value: "new"}},
+			},
+		},
+		{
+			name:   "secret and prune",
+			secret: true,
+			prune:  true,
+			expected: &EnvChanges{
+				Create: []api.EnvVar{{Key: "API_TOKEN", Type: api.EnvVarPassword, Value: "secret"}},
+				Update: []api.EnvVar{{Key: "DB_PASSWORD", Type: api.EnvVarPassword, Value: "new"}},
+				Delete: []api.EnvVar{current[2]},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			if got := PlanEnv(current, vars, test.secret, test.prune); !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("PlanEnv() = %+v, expected %+v", got, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package flow
+
+import (
+	"os"
+	"os/exec"
+	"os/user"
+	"strings"
+
+	"go.mlcdf.fr/owh/internal/remote"
+)
+
+// DescribeDeployment fills the local user and, when dir belongs to a git
+// repository, the commit, branch and dirty state of the deployed sources.
+func DescribeDeployment(d *remote.Deployment, dir string) {
+	d.User = localUser()
+
+	commit, err := git(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return
+	}
+	d.Commit = commit
+
+	if branch, err := git(dir, "rev-parse", "--abbrev-ref", "HEAD"); err == nil && branch != "HEAD" {
+		d.Branch = branch
+	}
+
+	if status, err := git(dir, "status", "--porcelain"); err == nil {
+		d.Dirty = status != ""
+	}
+}
+
+func localUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+
+	return os.Getenv("USERNAME")
+}
+
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
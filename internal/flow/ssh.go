@@ -1,10 +1,17 @@
 package flow
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math/rand"
+	"net"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unicode"
 
@@ -12,101 +19,390 @@ import (
 	"go.mlcdf.fr/owh/internal/api"
 	"go.mlcdf.fr/owh/internal/cmdutil"
 	"go.mlcdf.fr/owh/internal/view"
+	"go.mlcdf.fr/sally/logging"
 
 	cfg "go.mlcdf.fr/owh/internal/config"
 	"go.mlcdf.fr/owh/internal/remote"
+	"golang.org/x/crypto/ssh"
 	"golang.org/x/xerrors"
 )
 
+// connections holds the connections opened by NewSSHClient, reused by the
+// commands running in the same process until closed.
+var connections = map[string]*remote.Client{}
+var connectionsMu sync.Mutex
+
+// NewSSHClient connects to hosting, or returns the connection already opened
+// to it.
 func NewSSHClient(client *api.Client, config *cfg.Config, view *view.View, isInteractive bool, hosting string) (*remote.Client, error) {
+	connectionsMu.Lock()
+	defer connectionsMu.Unlock()
+
+	if conn, ok := connections[hosting]; ok && !conn.Closed() {
+		return conn, nil
+	}
+
 	hostingInfo, err := client.GetHosting(hosting)
 	if err != nil {
 		return nil, err
 	}
 
-	credentials, ok := config.SFTPCredentials[hosting]
-	if !ok {
-		credentials = &cfg.Credentials{}
+	credentials := sshCredentials(config, hostingInfo.PrimaryLogin, hosting)
 
-		if password := os.Getenv(cfg.ENV_SSH_PASSWORD); password != "" {
-			credentials.Password = password
+	checker, err := HostKeyChecker(isInteractive)
+	if err != nil {
+		return nil, err
+	}
+
+	if credentials.Password == "" && credentials.Key == "" {
+		// the keys of the ssh-agent may be authorized on the hosting, the
+		// credentials are only asked for when they're refused
+		if remote.HasAgent() {
+			conn, err := dial(hostingInfo, credentials, checker, isInteractive)
+			if err == nil {
+				connections[hosting] = conn
+				return conn, nil
+			}
+
+			if !isAuthError(err) {
+				return nil, err
+			}
+
+			logging.Debugf("keys of the ssh-agent refused: %s", err)
 		}
 
-		if user := os.Getenv(cfg.ENV_SSH_USER); user != "" {
-			credentials.User = user
-		} else {
-			credentials.User = hostingInfo.PrimaryLogin
+		credentials, err = askCredentials(client, view, config, isInteractive, hostingInfo.PrimaryLogin, hosting)
+		if err != nil {
+			return nil, err
 		}
 	}
 
-	if credentials.Password == "" {
-		if !isInteractive {
-			fmt.Println("No SSH credentials found in config or environnement variable.")
-			return nil, cmdutil.ErrSilent
-		}
+	conn, err := dial(hostingInfo, credentials, checker, isInteractive)
+	if err != nil {
+		return nil, err
+	}
+
+	connections[hosting] = conn
+	return conn, nil
+}
+
+// askCredentials offers to create an SSH user or to reset the password of an
+// existing one, and returns the resulting credentials.
+func askCredentials(client *api.Client, view *view.View, config *cfg.Config, isInteractive bool, primaryLogin string, hosting string) (*cfg.Credentials, error) {
+	if !isInteractive {
+		fmt.Println("No SSH credentials found in config or environnement variable.")
+		return nil, cmdutil.ErrSilent
+	}
 
-		var input string
+	var input string
 
-		OPTION_CREATE_NEW_USER := "Create a new ssh user"
-		OPTION_RESET_PASSWORD := "Reset the password of the existing ssh user"
-		OPTION_ABORT_DEPLOYMENT := "Abort the deployment"
+	OPTION_CREATE_NEW_USER := "Create a new ssh user"
+	OPTION_RESET_PASSWORD := "Reset the password of the existing ssh user"
+	OPTION_ABORT_DEPLOYMENT := "Abort the deployment"
 
-		prompt := &survey.Select{
-			Message: "No SSH credentials found in config or environnement variable. What would you like to do?",
-			Options: []string{OPTION_CREATE_NEW_USER, OPTION_RESET_PASSWORD, OPTION_ABORT_DEPLOYMENT},
+	prompt := &survey.Select{
+		Message: "No SSH credentials found in config or environnement variable. What would you like to do?",
+		Options: []string{OPTION_CREATE_NEW_USER, OPTION_RESET_PASSWORD, OPTION_ABORT_DEPLOYMENT},
+	}
+	err := survey.AskOne(prompt, &input)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to display prompt %w", err)
+	}
+
+	switch input {
+	case OPTION_CREATE_NEW_USER:
+		credentials, err := createSSHUser(client, view, config, primaryLogin, hosting)
+		if err != nil {
+			return nil, err
 		}
-		err := survey.AskOne(prompt, &input)
+		fmt.Printf("SSH user %s created\n", credentials.User)
+
+		return credentials, nil
+	case OPTION_RESET_PASSWORD:
+		users, err := client.ListUsers(hosting)
 		if err != nil {
-			return nil, xerrors.Errorf("failed to display prompt %w", err)
+			return nil, err
 		}
 
-		switch input {
-		case OPTION_CREATE_NEW_USER:
-			credentials, err = createSSHUser(client, view, config, hostingInfo.PrimaryLogin, hosting)
-			if err != nil {
-				return nil, err
-			}
-			fmt.Printf("SSH user %s created\n", credentials.User)
-		case OPTION_RESET_PASSWORD:
-			users, err := client.ListUsers(hosting)
-			if err != nil {
-				return nil, err
-			}
+		var user string
+		prompt := &survey.Select{Message: "Users", Options: users}
 
-			var user string
-			prompt := &survey.Select{Message: "Users", Options: users}
+		err = survey.AskOne(prompt, &user)
+		if err != nil {
+			return nil, err
+		}
 
-			err = survey.AskOne(prompt, &user)
-			if err != nil {
-				return nil, err
-			}
+		err = ChangePassword(client, config, hosting, user, "")
+		if err != nil {
+			return nil, err
+		}
 
-			err = ChangePassword(client, config, hosting, user, "")
-			if err != nil {
-				return nil, err
-			}
-		case OPTION_ABORT_DEPLOYMENT:
-			fallthrough
-		default:
-			fmt.Println("Aborting the deployement")
-			return nil, cmdutil.ErrCancel
+		return config.SFTPCredentials[hosting], nil
+	case OPTION_ABORT_DEPLOYMENT:
+		fallthrough
+	default:
+		fmt.Println("Aborting the deployement")
+		return nil, cmdutil.ErrCancel
+	}
+}
+
+// dial connects to the hosting with credentials.
+func dial(hostingInfo *api.HostingInfo, credentials *cfg.Credentials, checker *remote.HostKeyChecker, isInteractive bool) (*remote.Client, error) {
+	auth, err := authMethods(credentials, isInteractive)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := connect(sshConfig(hostingInfo, credentials.User, auth, checker))
+	if err != nil {
+		if errors.Is(err, remote.ErrHostKeyUnknown) && !isInteractive {
+			fmt.Printf(
+				"Please set the %s environment variable to the fingerprint of the SSH host key of the hosting\n",
+				cfg.ENV_SSH_HOST_FINGERPRINT,
+			)
 		}
+		return nil, err
 	}
 
-	sshConfig := remote.NewPasswordConfig(
+	return conn, nil
+}
+
+// sshConfig returns the config connecting to the hosting as user. The host
+// key algorithms are limited to the types of the keys known for it.
+func sshConfig(hostingInfo *api.HostingInfo, user string, auth []ssh.AuthMethod, checker *remote.HostKeyChecker) *remote.Config {
+	config := remote.NewConfig(
 		hostingInfo.ServiceManagementAccess.SSH.URL,
 		hostingInfo.ServiceManagementAccess.SSH.Port,
-		credentials.User,
-		credentials.Password,
+		user,
+		auth,
+		checker.Callback(),
 	)
 
-	conn, err := remote.Connect(sshConfig)
+	config.SSHConfig.HostKeyAlgorithms = checker.Algorithms(net.JoinHostPort(config.Host, strconv.Itoa(config.Port)))
+
+	return config
+}
+
+// isAuthError reports whether err is the refusal of every auth method.
+func isAuthError(err error) bool {
+	return strings.Contains(err.Error(), "ssh: unable to authenticate")
+}
+
+// connect dials the hosting, giving up on Ctrl-C.
+func connect(config *remote.Config) (*remote.Client, error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	return remote.Connect(ctx, config)
+}
+
+// sshCredentials returns the credentials of the hosting from the config, or
+// else from the environment variables.
+func sshCredentials(config *cfg.Config, primaryLogin string, hosting string) *cfg.Credentials {
+	if credentials, ok := config.SFTPCredentials[hosting]; ok {
+		return credentials
+	}
+
+	credentials := &cfg.Credentials{
+		Password: os.Getenv(cfg.ENV_SSH_PASSWORD),
+		Key:      os.Getenv(cfg.ENV_SSH_KEY),
+	}
+
+	if user := os.Getenv(cfg.ENV_SSH_USER); user != "" {
+		credentials.User = user
+	} else {
+		credentials.User = primaryLogin
+	}
+
+	return credentials
+}
+
+// authMethods returns the ways to authenticate with credentials: the key
+// first, then the keys of the ssh-agent, then the password.
+func authMethods(credentials *cfg.Credentials, isInteractive bool) ([]ssh.AuthMethod, error) {
+	auth := []ssh.AuthMethod{}
+
+	if credentials.Key != "" {
+		signer, err := remote.KeySigner(credentials.Key, keyPassphrase(credentials.Key, isInteractive))
+		if err != nil {
+			return nil, err
+		}
+
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+
+	if agentAuth := remote.AgentAuth(); agentAuth != nil {
+		auth = append(auth, agentAuth)
+	}
+
+	if credentials.Password != "" {
+		auth = append(auth, ssh.RetryableAuthMethod(ssh.Password(credentials.Password), 5))
+	}
+
+	return auth, nil
+}
 
+// keyPassphrase returns a function asking the passphrase of the key at path,
+// or reading it from the OWH_SSH_KEY_PASSPHRASE environment variable.
+func keyPassphrase(path string, isInteractive bool) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		if passphrase := os.Getenv(cfg.ENV_SSH_KEY_PASSPHRASE); passphrase != "" {
+			return []byte(passphrase), nil
+		}
+
+		if !isInteractive {
+			return nil, xerrors.Errorf("key %s is encrypted, set the %s environment variable", path, cfg.ENV_SSH_KEY_PASSPHRASE)
+		}
+
+		var passphrase string
+		prompt := &survey.Password{Message: fmt.Sprintf("Passphrase for %s", path)}
+
+		if err := survey.AskOne(prompt, &passphrase); err != nil {
+			return nil, xerrors.Errorf("failed to display prompt %w", err)
+		}
+
+		return []byte(passphrase), nil
+	}
+}
+
+// InstallKey authorizes the public key of the private key at keyPath on the
+// hosting, over a password session. When keyPath is empty, the first key of
+// ~/.ssh is used, or a new one is generated. Once the key is known to work,
+// it replaces the password in the config.
+func InstallKey(client *api.Client, config *cfg.Config, view *view.View, isInteractive bool, hosting string, keyPath string) error {
+	hostingInfo, err := client.GetHosting(hosting)
+	if err != nil {
+		return err
+	}
+
+	conn, err := NewSSHClient(client, config, view, isInteractive, hosting)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if keyPath == "" {
+		keyPath = defaultKeyFile()
+	}
+
+	var public ssh.PublicKey
+
+	if keyPath == "" {
+		keyPath, err = cfg.KeyFile()
+		if err != nil {
+			return err
+		}
+
+		public, err = remote.GenerateKey(keyPath, "owh")
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Generated a new SSH key %s\n", cmdutil.Highlight(keyPath))
+	} else {
+		public, err = remote.PublicKey(keyPath, keyPassphrase(keyPath, isInteractive))
+		if err != nil {
+			return err
+		}
+	}
+
+	added, err := conn.AuthorizeKey(public, "owh")
+	if err != nil {
+		return err
+	}
+
+	if added {
+		fmt.Printf("Key %s added to ~/%s\n", cmdutil.Highlight(ssh.FingerprintSHA256(public)), remote.AuthorizedKeysFile)
+	} else {
+		fmt.Printf("Key %s is already authorized\n", cmdutil.Highlight(ssh.FingerprintSHA256(public)))
+	}
+
+	credentials := *sshCredentials(config, hostingInfo.PrimaryLogin, hosting)
+	credentials.Key = keyPath
+	credentials.Password = ""
+
+	auth, err := authMethods(&credentials, isInteractive)
+	if err != nil {
+		return err
+	}
+
+	checker, err := HostKeyChecker(isInteractive)
+	if err != nil {
+		return err
+	}
+
+	check, err := connect(sshConfig(hostingInfo, credentials.User, auth, checker))
+	if err != nil {
+		return xerrors.Errorf("the key was installed but authenticating with it failed: %w", err)
+	}
+	check.Close()
+
+	config.SFTPCredentials[hosting] = &credentials
+	return config.Save()
+}
+
+// defaultKeyFile returns the first private key of ~/.ssh, or an empty string.
+func defaultKeyFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	for _, name := range remote.DefaultKeyFiles {
+		path := filepath.Join(home, ".ssh", name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+
+	return ""
+}
+
+// HostKeyChecker checks the key of the hosting against the known_hosts file
+// of owh and the one of OpenSSH. In interactive mode, an unknown key is
+// trusted on first use once confirmed. The fingerprint of the
+// OWH_SSH_HOST_FINGERPRINT environment variable, if set, is required instead.
+func HostKeyChecker(isInteractive bool) (*remote.HostKeyChecker, error) {
+	location, err := cfg.KnownHostsFile()
 	if err != nil {
 		return nil, err
 	}
 
-	return conn, nil
+	checker := &remote.HostKeyChecker{
+		Files:       []string{location},
+		Fingerprint: os.Getenv(cfg.ENV_SSH_HOST_FINGERPRINT),
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		checker.Files = append(checker.Files, filepath.Join(home, ".ssh", "known_hosts"))
+	}
+
+	if isInteractive {
+		checker.Confirm = confirmHostKey
+	}
+
+	return checker, nil
+}
+
+func confirmHostKey(hostname string, key ssh.PublicKey) (bool, error) {
+	fmt.Printf(
+		"The authenticity of host %s can't be established.\n%s key fingerprint is %s.\n",
+		cmdutil.Highlight(hostname),
+		key.Type(),
+		cmdutil.Highlight(ssh.FingerprintSHA256(key)),
+	)
+
+	var trust bool
+	prompt := &survey.Confirm{
+		Message: "Are you sure you want to continue connecting?",
+		Default: false,
+	}
+
+	if err := survey.AskOne(prompt, &trust); err != nil {
+		return false, xerrors.Errorf("failed to display prompt %w", err)
+	}
+
+	return trust, nil
 }
 
 func createSSHUser(client *api.Client, view *view.View, config *cfg.Config, primaryLogin string, hosting string) (*cfg.Credentials, error) {
@@ -219,7 +515,14 @@ func ChangePassword(client *api.Client, conf *cfg.Config, hosting string, user s
 		return err
 	}
 
-	conf.SFTPCredentials[hosting] = &cfg.Credentials{User: user, Password: password}
+	credentials := &cfg.Credentials{User: user, Password: password}
+
+	// the key keeps working after a password change
+	if previous, ok := conf.SFTPCredentials[hosting]; ok && previous.User == user {
+		credentials.Key = previous.Key
+	}
+
+	conf.SFTPCredentials[hosting] = credentials
 	err = conf.Save()
 	if err != nil {
 		return err
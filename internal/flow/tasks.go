@@ -66,7 +66,7 @@ func WaitTaskDone(client *api.Client, view *view.View, hosting string, id int64,
 
 		if task.Status == "error" || task.Status == "cancelled" {
 			view.StopSpinner()
-			view.Printf("Unexpected task status %s for ssh user creation (task_id: %d)\n", task.Status, id)
+			view.Printf("Unexpected task status %s for %s (task_id: %d)\n", task.Status, task.Function, id)
 			return cmdutil.ErrSilent
 		}
 
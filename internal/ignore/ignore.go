@@ -0,0 +1,220 @@
+// Package ignore matches paths against gitignore-style patterns.
+package ignore
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// Filename is the name of the file, at the root of the deployed directory,
+// holding additional patterns.
+const Filename = ".owhignore"
+
+// Defaults are the patterns applied before any user-defined one: dotfiles
+// are skipped, except the ones a web server needs, as well as dependency and
+// cache folders.
+var Defaults = []string{
+	".*",
+	"!.well-known",
+	"!.htaccess",
+	"!.ovhconfig",
+	"node_modules/",
+	"__pycache__/",
+}
+
+type rule struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// Matcher tells whether a path is ignored. Like with gitignore, the last
+// matching pattern wins and the content of an ignored directory is ignored
+// too.
+type Matcher struct {
+	rules []rule
+}
+
+// New returns a Matcher for the given patterns, in order of precedence.
+// Blank lines and lines starting with # are ignored.
+func New(patterns ...string) *Matcher {
+	m := &Matcher{}
+
+	for _, pattern := range patterns {
+		if r, ok := parse(pattern); ok {
+			m.rules = append(m.rules, r)
+		}
+	}
+
+	return m
+}
+
+// Default returns a Matcher for the Defaults patterns.
+func Default() *Matcher {
+	return New(Defaults...)
+}
+
+// Load returns a Matcher for the Defaults patterns, followed by the extra
+// patterns and the ones of the .owhignore file of dir, if any.
+func Load(dir string, extra []string) (*Matcher, error) {
+	patterns := append([]string{}, Defaults...)
+	patterns = append(patterns, extra...)
+
+	location := filepath.Join(dir, Filename)
+
+	f, err := os.Open(location)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return New(patterns...), nil
+		}
+		return nil, xerrors.Errorf("error opening %s: %w", location, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		patterns = append(patterns, scanner.Text())
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, xerrors.Errorf("error reading %s: %w", location, err)
+	}
+
+	return New(patterns...), nil
+}
+
+// Match reports whether path, relative to the root of the patterns, is
+// ignored. isDir tells whether path is a directory.
+func (m *Matcher) Match(path string, isDir bool) bool {
+	if m == nil {
+		return false
+	}
+
+	path = filepath.ToSlash(filepath.Clean(path))
+	if path == "." {
+		return false
+	}
+
+	parts := strings.Split(path, "/")
+	for i := 1; i < len(parts); i++ {
+		if m.match(strings.Join(parts[:i], "/"), true) {
+			return true
+		}
+	}
+
+	return m.match(path, isDir)
+}
+
+func (m *Matcher) match(path string, isDir bool) bool {
+	ignored := false
+
+	for _, r := range m.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+
+		if r.re.MatchString(path) {
+			ignored = !r.negate
+		}
+	}
+
+	return ignored
+}
+
+func parse(pattern string) (rule, bool) {
+	pattern = strings.TrimRight(pattern, " \r")
+
+	if pattern == "" || strings.HasPrefix(pattern, "#") {
+		return rule{}, false
+	}
+
+	var r rule
+
+	if strings.HasPrefix(pattern, "!") {
+		r.negate = true
+		pattern = pattern[1:]
+	} else if strings.HasPrefix(pattern, `\!`) || strings.HasPrefix(pattern, `\#`) {
+		pattern = pattern[1:]
+	}
+
+	if strings.HasSuffix(pattern, "/") {
+		r.dirOnly = true
+		pattern = strings.TrimSuffix(pattern, "/")
+	}
+
+	// A pattern containing a slash is relative to the root, otherwise it
+	// matches at any level.
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	if pattern == "" {
+		return rule{}, false
+	}
+
+	expr := globToRegexp(pattern)
+	if !anchored {
+		expr = "(.*/)?" + expr
+	}
+
+	re, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return rule{}, false
+	}
+
+	r.re = re
+	return r, true
+}
+
+func globToRegexp(pattern string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+
+		switch c {
+		case '*':
+			if strings.HasPrefix(pattern[i:], "**") {
+				switch {
+				case strings.HasPrefix(pattern[i:], "**/"):
+					// leading or inner **/ matches zero or more directories
+					b.WriteString("(.*/)?")
+					i += 2
+				default:
+					// trailing ** matches everything inside
+					b.WriteString(".*")
+					i++
+				}
+				continue
+			}
+			b.WriteString("[^/]*")
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				b.WriteString(regexp.QuoteMeta(string(pattern[i])))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,52 @@
+package ignore
+
+import (
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		patterns []string
+		path     string
+		isDir    bool
+		want     bool
+	}{
+		{name: "normal file", patterns: Defaults, path: "index.html", want: false},
+		{name: "git folder", patterns: Defaults, path: ".git", isDir: true, want: true},
+		{name: "file inside git folder", patterns: Defaults, path: ".git/FETCH_HEAD", want: true},
+		{name: ".htaccess file", patterns: Defaults, path: "blog/.htaccess", want: false},
+		{name: ".ovhconfig file", patterns: Defaults, path: ".ovhconfig", want: false},
+		{name: "file inside .well-known", patterns: Defaults, path: ".well-known/security.txt", want: false},
+		{name: "file inside node_modules", patterns: Defaults, path: "node_modules/yolo/index.js", want: true},
+		{name: "nested node_modules", patterns: Defaults, path: "app/node_modules/yolo/index.js", want: true},
+		{name: "node_modules in a name", patterns: Defaults, path: "my.node_modules_notes/index.html", want: false},
+		{name: "python cache", patterns: Defaults, path: "app/__pycache__/x.pyc", want: true},
+		{name: "basename pattern", patterns: []string{"*.map"}, path: "js/app.js.map", want: true},
+		{name: "anchored pattern", patterns: []string{"/drafts"}, path: "blog/drafts", isDir: true, want: false},
+		{name: "anchored pattern at root", patterns: []string{"/drafts"}, path: "drafts/post.html", want: true},
+		{name: "pattern with a slash is anchored", patterns: []string{"doc/*.md"}, path: "src/doc/a.md", want: false},
+		{name: "directory only pattern on a file", patterns: []string{"build/"}, path: "build", want: false},
+		{name: "double star", patterns: []string{"**/tmp/**"}, path: "a/b/tmp/c/d.txt", want: true},
+		{name: "negation", patterns: []string{"*.log", "!keep.log"}, path: "logs/keep.log", want: false},
+		{name: "last pattern wins", patterns: []string{"!keep.log", "*.log"}, path: "keep.log", want: true},
+		{name: "no re-include inside an ignored directory", patterns: []string{"logs/", "!logs/keep.log"}, path: "logs/keep.log", want: true},
+		{name: "comment", patterns: []string{"# index.html"}, path: "index.html", want: false},
+		{name: "escaped hash", patterns: []string{`\#notes`}, path: "#notes", want: true},
+		{name: "character class", patterns: []string{"img[0-9].png"}, path: "img7.png", want: true},
+	}
+
+	for _, test := range testCases {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := New(test.patterns...).Match(test.path, test.isDir); got != test.want {
+				t.Errorf("want Match=%t, got %t for %s", test.want, got, test.path)
+			}
+		})
+	}
+}
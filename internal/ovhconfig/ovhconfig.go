@@ -0,0 +1,150 @@
+// Package ovhconfig reads and writes .ovhconfig files, which set the runtime
+// of the websites of an OVHcloud Web Hosting.
+package ovhconfig
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+)
+
+// Filename is the name of the file, at the root of a website, the hosting
+// reads its runtime from.
+const Filename = ".ovhconfig"
+
+var (
+	Engines      = []string{"php", "phpcgi"}
+	Environments = []string{"production", "development"}
+	Firewalls    = []string{"none", "security"}
+)
+
+// Config is the runtime of a website.
+type Config struct {
+	// Engine is php, or phpcgi for the legacy engine.
+	Engine        string
+	EngineVersion string
+	// Environment is production, which caches the PHP files, or
+	// development.
+	Environment string
+	// HTTPFirewall is none or security.
+	HTTPFirewall string
+	// Container is the image the website runs in, such as stable64.
+	Container string
+}
+
+// Default returns the configuration applied by the hosting when there's no
+// .ovhconfig file.
+func Default() *Config {
+	return &Config{
+		Engine:       "php",
+		Environment:  "production",
+		HTTPFirewall: "none",
+		Container:    "stable64",
+	}
+}
+
+// Parse reads a .ovhconfig file. The keys it doesn't know are ignored.
+func Parse(r io.Reader) (*Config, error) {
+	config := &Config{}
+	scanner := bufio.NewScanner(r)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(text, "=")
+		if !ok {
+			return nil, xerrors.Errorf("line %d: expected key=value, got %q", line, text)
+		}
+
+		value = strings.TrimSpace(value)
+
+		switch strings.TrimSpace(key) {
+		case "app.engine":
+			config.Engine = value
+		case "app.engine.version":
+			config.EngineVersion = value
+		case "environment":
+			config.Environment = value
+		case "http.firewall":
+			config.HTTPFirewall = value
+		case "container.image":
+			config.Container = value
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// Load reads the .ovhconfig file at path.
+func Load(path string) (*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	config, err := Parse(f)
+	if err != nil {
+		return nil, xerrors.Errorf("error parsing %s: %w", path, err)
+	}
+
+	return config, nil
+}
+
+// Validate checks that the values are ones the hosting accepts.
+func (config *Config) Validate() error {
+	if !slices.Contains(Engines, config.Engine) {
+		return xerrors.Errorf("invalid engine %q, expected one of %s", config.Engine, strings.Join(Engines, ", "))
+	}
+
+	if config.EngineVersion == "" {
+		return xerrors.New("missing engine version")
+	}
+
+	if !slices.Contains(Environments, config.Environment) {
+		return xerrors.Errorf("invalid environment %q, expected one of %s", config.Environment, strings.Join(Environments, ", "))
+	}
+
+	if !slices.Contains(Firewalls, config.HTTPFirewall) {
+		return xerrors.Errorf("invalid http firewall %q, expected one of %s", config.HTTPFirewall, strings.Join(Firewalls, ", "))
+	}
+
+	if config.Container == "" {
+		return xerrors.New("missing container image")
+	}
+
+	return nil
+}
+
+// String returns the content of the .ovhconfig file.
+func (config *Config) String() string {
+	return fmt.Sprintf(
+		"app.engine=%s\napp.engine.version=%s\nhttp.firewall=%s\nenvironment=%s\ncontainer.image=%s\n",
+		config.Engine,
+		config.EngineVersion,
+		config.HTTPFirewall,
+		config.Environment,
+		config.Container,
+	)
+}
+
+// Save writes the configuration to the .ovhconfig file at path.
+func (config *Config) Save(path string) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, []byte(config.String()), 0644)
+}
@@ -0,0 +1,90 @@
+package ovhconfig
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	content := `
+# runtime of the website
+app.engine=php
+app.engine.version = 8.2
+http.firewall=none
+environment=development
+container.image=stable64
+app.unknown=yes
+`
+
+	config, err := Parse(strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &Config{
+		Engine:        "php",
+		EngineVersion: "8.2",
+		Environment:   "development",
+		HTTPFirewall:  "none",
+		Container:     "stable64",
+	}
+
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("Parse() = %+v, expected %+v", config, expected)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	if _, err := Parse(strings.NewReader("app.engine php\n")); err == nil {
+		t.Error("Parse() succeeded, expected an error")
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	config := Default()
+	config.EngineVersion = "8.1"
+
+	path := filepath.Join(t.TempDir(), Filename)
+
+	if err := config.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(loaded, config) {
+		t.Errorf("Load() = %+v, expected %+v", loaded, config)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		valid  bool
+	}{
+		{name: "valid", modify: func(c *Config) {}, valid: true},
+		{name: "unknown engine", modify: func(c *Config) { c.Engine = "ruby" }},
+		{name: "no version", modify: func(c *Config) { c.EngineVersion = "" }},
+		{name: "unknown environment", modify: func(c *Config) { c.Environment = "staging" }},
+		{name: "unknown firewall", modify: func(c *Config) { c.HTTPFirewall = "strict" }},
+		{name: "no container", modify: func(c *Config) { c.Container = "" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Default()
+			config.EngineVersion = "8.2"
+			tt.modify(config)
+
+			if err := config.Validate(); (err == nil) != tt.valid {
+				t.Errorf("Validate() = %v, expected valid: %v", err, tt.valid)
+			}
+		})
+	}
+}
@@ -0,0 +1,281 @@
+package remote
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.mlcdf.fr/sally/logging"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/xerrors"
+)
+
+// DefaultKeyFiles are the private keys looked up in ~/.ssh, like OpenSSH does.
+var DefaultKeyFiles = []string{"id_ed25519", "id_ecdsa", "id_rsa"}
+
+// AuthorizedKeysFile is the location of the authorized_keys file, relative to
+// the hosting home.
+const AuthorizedKeysFile = ".ssh/authorized_keys"
+
+// NewConfig returns a ssh.ClientConfig authenticating with auth.
+func NewConfig(host string, port int, user string, auth []ssh.AuthMethod, hostKeyCallback ssh.HostKeyCallback) *Config {
+	return &Config{
+		Host: host,
+		Port: port,
+		SSHConfig: &ssh.ClientConfig{
+			Config:          ssh.Config{},
+			User:            user,
+			Auth:            auth,
+			HostKeyCallback: hostKeyCallback,
+			Timeout:         10 * time.Second,
+		},
+	}
+}
+
+// KeySigner returns the signer of the private key at path. The ssh-agent of
+// SSH_AUTH_SOCK is used when it holds the key, otherwise the key is read
+// from the file and passphrase is called if it's encrypted.
+func KeySigner(path string, passphrase func() ([]byte, error)) (ssh.Signer, error) {
+	if public, err := readPublicKey(path + ".pub"); err == nil {
+		if signer := agentSigner(public); signer != nil {
+			return signer, nil
+		}
+	}
+
+	pemBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, xerrors.Errorf("error reading key %s: %w", path, err)
+	}
+
+	signer, err := ssh.ParsePrivateKey(pemBytes)
+
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		secret, err := passphrase()
+		if err != nil {
+			return nil, err
+		}
+
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(pemBytes, secret)
+		if err != nil {
+			return nil, xerrors.Errorf("error decrypting key %s: %w", path, err)
+		}
+
+		return signer, nil
+	}
+
+	if err != nil {
+		return nil, xerrors.Errorf("error parsing key %s: %w", path, err)
+	}
+
+	return signer, nil
+}
+
+// PublicKey returns the public key of the private key at path, read from
+// path.pub when it exists.
+func PublicKey(path string, passphrase func() ([]byte, error)) (ssh.PublicKey, error) {
+	if public, err := readPublicKey(path + ".pub"); err == nil {
+		return public, nil
+	}
+
+	signer, err := KeySigner(path, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	return signer.PublicKey(), nil
+}
+
+func readPublicKey(path string) (ssh.PublicKey, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	public, _, _, _, err := ssh.ParseAuthorizedKey(b)
+	return public, err
+}
+
+// AgentAuth returns the method authenticating with the keys of the
+// ssh-agent of SSH_AUTH_SOCK, or nil when there's no agent. The connection to
+// the agent is kept open since the method relies on it.
+func AgentAuth() ssh.AuthMethod {
+	conn := dialAgent()
+	if conn == nil {
+		return nil
+	}
+
+	return ssh.PublicKeysCallback(agent.NewClient(conn).Signers)
+}
+
+// HasAgent reports whether an ssh-agent is reachable through SSH_AUTH_SOCK.
+func HasAgent() bool {
+	conn := dialAgent()
+	if conn == nil {
+		return false
+	}
+
+	conn.Close()
+	return true
+}
+
+func dialAgent() net.Conn {
+	socket := os.Getenv("SSH_AUTH_SOCK")
+	if socket == "" {
+		return nil
+	}
+
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		logging.Debugf("failed to connect to ssh-agent: %s", err)
+		return nil
+	}
+
+	return conn
+}
+
+// agentSigner returns the signer of the ssh-agent for public, or nil. The
+// connection to the agent is kept open since the signer relies on it.
+func agentSigner(public ssh.PublicKey) ssh.Signer {
+	conn := dialAgent()
+	if conn == nil {
+		return nil
+	}
+
+	signers, err := agent.NewClient(conn).Signers()
+	if err != nil {
+		logging.Debugf("failed to list ssh-agent keys: %s", err)
+		conn.Close()
+		return nil
+	}
+
+	for _, signer := range signers {
+		if bytes.Equal(signer.PublicKey().Marshal(), public.Marshal()) {
+			return signer
+		}
+	}
+
+	conn.Close()
+	return nil
+}
+
+// GenerateKey creates an unencrypted ed25519 key at path, and its public key
+// at path.pub.
+func GenerateKey(path string, comment string) (ssh.PublicKey, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to generate key: %w", err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to encode key: %w", err)
+	}
+
+	sshPublic, err := ssh.NewPublicKey(public)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, xerrors.Errorf("error creating %s directory %w", filepath.Dir(path), err)
+	}
+
+	err = os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600)
+	if err != nil {
+		return nil, xerrors.Errorf("error writing %s: %w", path, err)
+	}
+
+	line := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPublic))) + " " + comment + "\n"
+	if err := os.WriteFile(path+".pub", []byte(line), 0644); err != nil {
+		return nil, xerrors.Errorf("error writing %s: %w", path+".pub", err)
+	}
+
+	return sshPublic, nil
+}
+
+// AuthorizeKey adds public to the authorized_keys of the hosting user. It
+// returns false when the key was already authorized.
+func (c *Client) AuthorizeKey(public ssh.PublicKey, comment string) (bool, error) {
+	client, err := c.session()
+	if err != nil {
+		return false, err
+	}
+
+	dir := filepath.Dir(AuthorizedKeysFile)
+
+	if err := client.MkdirAll(dir); err != nil {
+		return false, xerrors.Errorf("error creating %s directory %w", dir, err)
+	}
+
+	// sshd ignores the keys if the directory or file is writable by others
+	if err := client.Chmod(dir, 0700); err != nil {
+		return false, xerrors.Errorf("error setting mode of %s: %w", dir, err)
+	}
+
+	content := []byte{}
+
+	f, err := client.Open(AuthorizedKeysFile)
+	if err == nil {
+		defer f.Close()
+
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := scanner.Bytes()
+
+			key, _, _, _, err := ssh.ParseAuthorizedKey(line)
+			if err == nil && bytes.Equal(key.Marshal(), public.Marshal()) {
+				return false, nil
+			}
+
+			content = append(content, line...)
+			content = append(content, '\n')
+		}
+
+		if err := scanner.Err(); err != nil {
+			return false, xerrors.Errorf("error reading %s: %w", AuthorizedKeysFile, err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return false, xerrors.Errorf("error opening %s: %w", AuthorizedKeysFile, err)
+	}
+
+	line := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(public)))
+	if comment != "" {
+		line += " " + comment
+	}
+	content = append(content, line+"\n"...)
+
+	// the file is replaced in one step so a failure can't lock the user out
+	tmp := AuthorizedKeysFile + ".owh-tmp"
+
+	w, err := client.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return false, xerrors.Errorf("error creating %s: %w", tmp, err)
+	}
+
+	if _, err := w.Write(content); err != nil {
+		w.Close()
+		return false, xerrors.Errorf("error writing %s: %w", tmp, err)
+	}
+	w.Close()
+
+	if err := client.Chmod(tmp, 0600); err != nil {
+		return false, xerrors.Errorf("error setting mode of %s: %w", tmp, err)
+	}
+
+	if err := client.PosixRename(tmp, AuthorizedKeysFile); err != nil {
+		return false, xerrors.Errorf("error moving %s to %s: %w", tmp, AuthorizedKeysFile, err)
+	}
+
+	return true, nil
+}
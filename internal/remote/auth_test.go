@@ -0,0 +1,151 @@
+package remote
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+func noPassphrase() ([]byte, error) {
+	return nil, errors.New("no passphrase expected")
+}
+
+func TestGenerateKey(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+
+	path := filepath.Join(t.TempDir(), "owh", "id_ed25519")
+
+	public, err := GenerateKey(path, "owh")
+	require.NoError(t, err)
+
+	signer, err := KeySigner(path, noPassphrase)
+	require.NoError(t, err)
+	require.Equal(t, public.Marshal(), signer.PublicKey().Marshal())
+
+	read, err := PublicKey(path, noPassphrase)
+	require.NoError(t, err)
+	require.Equal(t, public.Marshal(), read.Marshal())
+}
+
+func TestKeySignerWithPassphrase(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalECPrivateKey(private)
+	require.NoError(t, err)
+
+	//nolint:staticcheck // legacy PEM encryption is enough to get an encrypted key
+	block, err := x509.EncryptPEMBlock(rand.Reader, "EC PRIVATE KEY", der, []byte("secret"), x509.PEMCipherAES256)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "id_ecdsa")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(block), 0o600))
+
+	_, err = KeySigner(path, noPassphrase)
+	require.Error(t, err)
+
+	signer, err := KeySigner(path, func() ([]byte, error) { return []byte("secret"), nil })
+	require.NoError(t, err)
+
+	want, err := ssh.NewPublicKey(&private.PublicKey)
+	require.NoError(t, err)
+	require.Equal(t, want.Marshal(), signer.PublicKey().Marshal())
+}
+
+func TestAgentAuth(t *testing.T) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	keyring := agent.NewKeyring()
+	require.NoError(t, keyring.Add(agent.AddedKey{PrivateKey: private}))
+
+	// unix socket paths are limited to ~100 bytes, t.TempDir() can be longer
+	dir, err := os.MkdirTemp("", "owh-agent")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	socket := filepath.Join(dir, "agent.sock")
+	agentListener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	t.Cleanup(func() { agentListener.Close() })
+
+	go func() {
+		for {
+			conn, err := agentListener.Accept()
+			if err != nil {
+				return
+			}
+			go agent.ServeAgent(keyring, conn) //nolint:errcheck
+		}
+	}()
+
+	t.Setenv("SSH_AUTH_SOCK", socket)
+	require.True(t, HasAgent())
+
+	signer, err := ssh.NewSignerFromKey(private)
+	require.NoError(t, err)
+
+	hostKey, err := ssh.NewSignerFromKey(private)
+	require.NoError(t, err)
+
+	serverConfig := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if bytes.Equal(key.Marshal(), signer.PublicKey().Marshal()) {
+				return nil, nil
+			}
+			return nil, errors.New("unknown key")
+		},
+	}
+	serverConfig.AddHostKey(hostKey)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		_, chans, reqs, err := ssh.NewServerConn(conn, serverConfig)
+		if err != nil {
+			return
+		}
+		go ssh.DiscardRequests(reqs)
+		for ch := range chans {
+			ch.Reject(ssh.Prohibited, "") //nolint:errcheck
+		}
+	}()
+
+	auth := AgentAuth()
+	require.NotNil(t, auth)
+
+	client, err := ssh.Dial("tcp", listener.Addr().String(), &ssh.ClientConfig{
+		User:            "user",
+		Auth:            []ssh.AuthMethod{auth},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(), //nolint:gosec
+	})
+	require.NoError(t, err, "authenticating with the agent only")
+	client.Close()
+
+	t.Setenv("SSH_AUTH_SOCK", "")
+	require.Nil(t, AgentAuth())
+	require.False(t, HasAgent())
+}
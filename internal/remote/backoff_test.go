@@ -0,0 +1,28 @@
+package remote
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	t.Parallel()
+
+	for attempt := 1; attempt < 10; attempt++ {
+		max := dialBackoff << (attempt - 1)
+		if max > maxDialBackoff {
+			max = maxDialBackoff
+		}
+
+		for i := 0; i < 20; i++ {
+			if got := backoff(attempt); got < max/2 || got > max {
+				t.Errorf("want a delay between %s and %s for attempt %d, got %s", max/2, max, attempt, got)
+			}
+		}
+	}
+
+	// the delays grow
+	if backoff(1) > 500*time.Millisecond || backoff(5) < 4*time.Second {
+		t.Errorf("want exponential delays")
+	}
+}
@@ -0,0 +1,140 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/xerrors"
+)
+
+// ExecOptions tunes a command run by Exec.
+type ExecOptions struct {
+	// Dir is the directory the command is run from, relative to the home of
+	// the user. Defaults to the home.
+	Dir string
+	// Env holds environment variables set for the command.
+	Env map[string]string
+	// Stdin is sent to the command, if set.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+var envNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ExitError is returned by Run when the remote command fails.
+type ExitError struct {
+	Code   int
+	Stderr string
+}
+
+func (e *ExitError) Error() string {
+	if e.Stderr == "" {
+		return fmt.Sprintf("command exited with code %d", e.Code)
+	}
+	return fmt.Sprintf("command exited with code %d: %s", e.Code, strings.TrimSpace(e.Stderr))
+}
+
+// Exec runs cmd on the hosting, streaming its output as it comes, and
+// returns its exit code. The command is killed when ctx is done.
+func (c *Client) Exec(ctx context.Context, cmd string, opts ExecOptions) (int, error) {
+	for key := range opts.Env {
+		if !envNameRe.MatchString(key) {
+			return -1, xerrors.Errorf("invalid environment variable name %q", key)
+		}
+	}
+
+	session, err := c.conn.NewSession()
+	if err != nil {
+		return -1, xerrors.Errorf("failed to open session: %w", err)
+	}
+	defer session.Close()
+
+	session.Stdin = opts.Stdin
+	session.Stdout = opts.Stdout
+	session.Stderr = opts.Stderr
+
+	if err := session.Start(commandLine(cmd, opts)); err != nil {
+		return -1, xerrors.Errorf("failed to run command %s: %w", cmd, err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- session.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		return exitCode(err)
+	case <-ctx.Done():
+		// not every server handles signals, closing the session hangs up
+		// the command anyway
+		_ = session.Signal(ssh.SIGKILL)
+		session.Close()
+		return -1, xerrors.Errorf("command %s interrupted: %w", cmd, ctx.Err())
+	}
+}
+
+// commandLine prefixes cmd with what's needed to run it from opts.Dir with
+// opts.Env. The environment variables are exported by the shell since sshd
+// usually refuses the ones sent by the client.
+func commandLine(cmd string, opts ExecOptions) string {
+	var b strings.Builder
+
+	keys := make([]string, 0, len(opts.Env))
+	for key := range opts.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fmt.Fprintf(&b, "export %s=%s; ", key, shellQuote(opts.Env[key]))
+	}
+
+	if opts.Dir != "" {
+		// Exit rather than chain with && so that no part of a compound
+		// command runs outside of Dir.
+		fmt.Fprintf(&b, "cd %s || exit 1; ", shellQuote(opts.Dir))
+	}
+
+	b.WriteString(cmd)
+	return b.String()
+}
+
+// Run runs cmd and returns its output. When the command fails, the error is
+// an *ExitError holding what it wrote to stderr.
+func (c *Client) Run(cmd string) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	code, err := c.Exec(context.Background(), cmd, ExecOptions{Stdout: &stdout, Stderr: &stderr})
+	if err != nil {
+		return "", err
+	}
+
+	if code != 0 {
+		return stdout.String(), xerrors.Errorf("failed to run command %s: %w", cmd, &ExitError{Code: code, Stderr: stderr.String()})
+	}
+
+	return stdout.String(), nil
+}
+
+// Stream runs cmd from the dir directory, writing its output to stdout and
+// stderr as it comes.
+func (c *Client) Stream(dir string, cmd string, stdout io.Writer, stderr io.Writer) error {
+	code, err := c.Exec(context.Background(), cmd, ExecOptions{Dir: dir, Stdout: stdout, Stderr: stderr})
+	if err != nil {
+		return err
+	}
+
+	if code != 0 {
+		return &ExitError{Code: code}
+	}
+
+	return nil
+}
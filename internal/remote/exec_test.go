@@ -0,0 +1,60 @@
+package remote
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestCommandLine(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+		opts ExecOptions
+		want string
+	}{
+		{name: "no options", want: "ls -la"},
+		{name: "dir", opts: ExecOptions{Dir: "www/my site"}, want: "cd 'www/my site' || exit 1; ls -la"},
+		{
+			name: "env",
+			opts: ExecOptions{Env: map[string]string{"B": "it's", "A": "1"}},
+			want: `export A='1'; export B='it'\''s'; ls -la`,
+		},
+		{
+			name: "env and dir",
+			opts: ExecOptions{Dir: "www", Env: map[string]string{"A": "1"}},
+			want: "export A='1'; cd 'www' || exit 1; ls -la",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := commandLine("ls -la", test.opts); got != test.want {
+				t.Errorf("want %s, got %s", test.want, got)
+			}
+		})
+	}
+}
+
+func TestCommandLineMissingDir(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "missing")
+	line := commandLine("echo first; echo second", ExecOptions{Dir: dir})
+
+	out, err := exec.Command("sh", "-c", line).Output()
+
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok || exitErr.ExitCode() != 1 {
+		t.Errorf("want exit code 1, got %v", err)
+	}
+
+	if len(out) != 0 {
+		t.Errorf("want no output, got %q", out)
+	}
+}
@@ -0,0 +1,149 @@
+package remote
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// HistoryDir is the directory, relative to the hosting home, holding the
+// deployment history of every website.
+const HistoryDir = ".owh/history"
+
+// Deployment is an entry of the deployment history of a website.
+type Deployment struct {
+	Release  string        `json:"release"`
+	Date     time.Time     `json:"date"`
+	User     string        `json:"user"`
+	Duration time.Duration `json:"duration"`
+
+	Commit string `json:"commit,omitempty"`
+	Branch string `json:"branch,omitempty"`
+	Dirty  bool   `json:"dirty,omitempty"`
+
+	Uploaded int64 `json:"uploaded"`
+	Skipped  int64 `json:"skipped"`
+	Deleted  int64 `json:"deleted"`
+	Bytes    int64 `json:"bytes"`
+
+	Added    []string `json:"added,omitempty"`
+	Modified []string `json:"modified,omitempty"`
+	Removed  []string `json:"removed,omitempty"`
+}
+
+// NewDeployment returns the history entry of a deployment of the release
+// which made the changes of stats.
+func NewDeployment(release string, stats *SyncStats) *Deployment {
+	d := &Deployment{
+		Release:  release,
+		Date:     time.Now(),
+		Uploaded: stats.Uploaded,
+		Skipped:  stats.Skipped,
+		Deleted:  stats.Deleted,
+		Bytes:    stats.Bytes,
+	}
+
+	if stats.Plan != nil {
+		for _, file := range stats.Plan.Add {
+			d.Added = append(d.Added, file.Path)
+		}
+
+		for _, file := range stats.Plan.Modify {
+			d.Modified = append(d.Modified, file.Path)
+		}
+
+		d.Removed = append(d.Removed, stats.Plan.removals()...)
+	}
+
+	return d
+}
+
+// HistoryPath returns the location of the deployment history of dest.
+func HistoryPath(dest string) string {
+	return filepath.Join(HistoryDir, dest+".jsonl")
+}
+
+// AppendHistory adds d at the end of the deployment history of dest.
+func (c *Client) AppendHistory(dest string, d *Deployment) error {
+	client, err := c.session()
+	if err != nil {
+		return err
+	}
+
+	location := HistoryPath(dest)
+
+	if err := client.MkdirAll(filepath.Dir(location)); err != nil {
+		return xerrors.Errorf("error creating %s directory %w", filepath.Dir(location), err)
+	}
+
+	b, err := json.Marshal(d)
+	if err != nil {
+		return xerrors.Errorf("failed to encode deployment: %w", err)
+	}
+
+	f, err := client.OpenFile(location, os.O_WRONLY|os.O_CREATE|os.O_APPEND)
+	if err != nil {
+		return xerrors.Errorf("error opening %s: %w", location, err)
+	}
+	defer f.Close()
+
+	// Not every server honours O_APPEND, write at the end explicitly
+	if _, err := f.Seek(0, io.SeekEnd); err != nil {
+		return xerrors.Errorf("error seeking end of %s: %w", location, err)
+	}
+
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		return xerrors.Errorf("error writing %s: %w", location, err)
+	}
+
+	return nil
+}
+
+// History returns the deployment history of dest, oldest first.
+func (c *Client) History(dest string) ([]Deployment, error) {
+	client, err := c.session()
+	if err != nil {
+		return nil, err
+	}
+
+	location := HistoryPath(dest)
+
+	f, err := client.Open(location)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []Deployment{}, nil
+		}
+		return nil, xerrors.Errorf("error opening %s: %w", location, err)
+	}
+	defer f.Close()
+
+	history := []Deployment{}
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var d Deployment
+		if err := json.Unmarshal(scanner.Bytes(), &d); err != nil {
+			return nil, xerrors.Errorf("invalid entry in %s: %w", location, err)
+		}
+
+		history = append(history, d)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, xerrors.Errorf("error reading %s: %w", location, err)
+	}
+
+	return history, nil
+}
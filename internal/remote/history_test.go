@@ -0,0 +1,26 @@
+package remote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDeployment(t *testing.T) {
+	t.Parallel()
+
+	plan := newPlan()
+	plan.Add = []PlanFile{{Path: "new.html"}}
+	plan.Modify = []PlanFile{{Path: "index.html"}}
+	plan.Delete = []PlanFile{{Path: "old/a.html"}, {Path: "stale.html"}}
+	plan.Rmdir = []string{"old"}
+
+	stats := &SyncStats{Uploaded: 2, Skipped: 10, Deleted: 2, Bytes: 42, Plan: plan}
+
+	d := NewDeployment("20240101000000", stats)
+	require.Equal(t, "20240101000000", d.Release)
+	require.Equal(t, []string{"new.html"}, d.Added)
+	require.Equal(t, []string{"index.html"}, d.Modified)
+	require.Equal(t, []string{"old", "stale.html"}, d.Removed)
+	require.Equal(t, int64(42), d.Bytes)
+}
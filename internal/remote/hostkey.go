@@ -0,0 +1,178 @@
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"golang.org/x/xerrors"
+)
+
+var ErrHostKeyMismatch = errors.New("host key mismatch")
+var ErrHostKeyUnknown = errors.New("unknown host key")
+
+// HostKeyChecker verifies the key presented by the hosting against
+// known_hosts files, like OpenSSH does.
+type HostKeyChecker struct {
+	// Files are the known_hosts files to read. Keys accepted on first use
+	// are added to the first one.
+	Files []string
+	// Fingerprint, when set, is the SHA256 fingerprint the key must have.
+	// It takes precedence over the known_hosts files.
+	Fingerprint string
+	// Confirm asks whether to trust a key seen for the first time. When
+	// nil, unknown keys are rejected.
+	Confirm func(hostname string, key ssh.PublicKey) (bool, error)
+}
+
+// Callback returns the ssh.HostKeyCallback implementing the checks.
+func (h *HostKeyChecker) Callback() ssh.HostKeyCallback {
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		fingerprint := ssh.FingerprintSHA256(key)
+
+		if h.Fingerprint != "" {
+			if !sameFingerprint(h.Fingerprint, fingerprint) {
+				return xerrors.Errorf(
+					"%w for %s: got %s, want %s. Someone could be eavesdropping on you",
+					ErrHostKeyMismatch, hostname, fingerprint, h.Fingerprint,
+				)
+			}
+			return nil
+		}
+
+		err := h.check(hostname, remote, key)
+
+		var keyErr *knownhosts.KeyError
+		if !errors.As(err, &keyErr) {
+			return err
+		}
+
+		if len(keyErr.Want) > 0 {
+			known := make([]string, 0, len(keyErr.Want))
+			for _, want := range keyErr.Want {
+				known = append(known, fmt.Sprintf("%s (%s:%d)", ssh.FingerprintSHA256(want.Key), want.Filename, want.Line))
+			}
+
+			return xerrors.Errorf(
+				"%w for %s: got %s, want %s. Someone could be eavesdropping on you, or the key of the hosting changed",
+				ErrHostKeyMismatch, hostname, fingerprint, strings.Join(known, ", "),
+			)
+		}
+
+		if h.Confirm == nil {
+			return xerrors.Errorf("%w for %s (%s)", ErrHostKeyUnknown, hostname, fingerprint)
+		}
+
+		ok, err := h.Confirm(hostname, key)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			return xerrors.Errorf("%w for %s (%s): rejected", ErrHostKeyUnknown, hostname, fingerprint)
+		}
+
+		return h.add(hostname, key)
+	}
+}
+
+// Algorithms returns the host key algorithms of the keys known for hostname,
+// to be negotiated first like OpenSSH does, or nil when none is known. This
+// way a server also offering a key of another type than the known one isn't
+// reported as a mismatch.
+func (h *HostKeyChecker) Algorithms(hostname string) []string {
+	if h.Fingerprint != "" {
+		return nil
+	}
+
+	// a key of no known type lists every key known for hostname
+	err := h.check(hostname, &net.TCPAddr{IP: net.IPv4zero}, probeKey{})
+
+	var keyErr *knownhosts.KeyError
+	if !errors.As(err, &keyErr) || len(keyErr.Want) == 0 {
+		return nil
+	}
+
+	algorithms := []string{}
+	for _, want := range keyErr.Want {
+		switch keyType := want.Key.Type(); keyType {
+		case ssh.KeyAlgoRSA:
+			algorithms = append(algorithms, ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256, ssh.KeyAlgoRSA)
+		default:
+			algorithms = append(algorithms, keyType)
+		}
+	}
+
+	sort.Strings(algorithms)
+	return algorithms
+}
+
+// probeKey is a public key of a type no known_hosts entry has.
+type probeKey struct{}
+
+func (probeKey) Type() string                        { return "owh-probe" }
+func (probeKey) Marshal() []byte                     { return []byte("owh-probe") }
+func (probeKey) Verify([]byte, *ssh.Signature) error { return errors.New("probe key") }
+
+// check looks key up in the known_hosts files which exist.
+func (h *HostKeyChecker) check(hostname string, remote net.Addr, key ssh.PublicKey) error {
+	files := []string{}
+	for _, file := range h.Files {
+		if _, err := os.Stat(file); err == nil {
+			files = append(files, file)
+		}
+	}
+
+	// without any file, every key is unknown
+	if len(files) == 0 {
+		return &knownhosts.KeyError{}
+	}
+
+	callback, err := knownhosts.New(files...)
+	if err != nil {
+		return xerrors.Errorf("failed to read known hosts: %w", err)
+	}
+
+	return callback(hostname, remote, key)
+}
+
+// add appends key to the first known_hosts file.
+func (h *HostKeyChecker) add(hostname string, key ssh.PublicKey) error {
+	if len(h.Files) == 0 {
+		return nil
+	}
+
+	location := h.Files[0]
+
+	if err := os.MkdirAll(filepath.Dir(location), 0700); err != nil {
+		return xerrors.Errorf("error creating %s directory %w", filepath.Dir(location), err)
+	}
+
+	f, err := os.OpenFile(location, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return xerrors.Errorf("error opening %s: %w", location, err)
+	}
+	defer f.Close()
+
+	line := knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key)
+	if _, err := f.WriteString(line + "\n"); err != nil {
+		return xerrors.Errorf("error writing %s: %w", location, err)
+	}
+
+	return nil
+}
+
+func sameFingerprint(pinned string, fingerprint string) bool {
+	pinned = strings.TrimSpace(pinned)
+	if !strings.HasPrefix(pinned, "SHA256:") {
+		pinned = "SHA256:" + pinned
+	}
+
+	return pinned == fingerprint
+}
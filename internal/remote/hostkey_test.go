@@ -0,0 +1,121 @@
+package remote
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func newHostKey(t *testing.T) ssh.PublicKey {
+	t.Helper()
+
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	key, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+
+	return key
+}
+
+func TestHostKeyChecker(t *testing.T) {
+	t.Parallel()
+
+	hostname := "ssh.cluster000.hosting.ovh.net:22"
+	addr := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 22}
+
+	key := newHostKey(t)
+	other := newHostKey(t)
+
+	checker := &HostKeyChecker{Files: []string{filepath.Join(t.TempDir(), "owh", "known_hosts")}}
+
+	err := checker.Callback()(hostname, addr, key)
+	require.ErrorIs(t, err, ErrHostKeyUnknown, "unknown keys must be rejected without confirmation")
+
+	checker.Confirm = func(string, ssh.PublicKey) (bool, error) { return true, nil }
+	require.NoError(t, checker.Callback()(hostname, addr, key), "key must be trusted on first use")
+
+	checker.Confirm = nil
+	require.NoError(t, checker.Callback()(hostname, addr, key), "key must be known once trusted")
+
+	err = checker.Callback()(hostname, addr, other)
+	require.ErrorIs(t, err, ErrHostKeyMismatch)
+
+	pinned := &HostKeyChecker{Fingerprint: ssh.FingerprintSHA256(other)}
+	require.NoError(t, pinned.Callback()(hostname, addr, other))
+	require.ErrorIs(t, pinned.Callback()(hostname, addr, key), ErrHostKeyMismatch)
+}
+
+func TestHostKeyCheckerAlgorithms(t *testing.T) {
+	t.Parallel()
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	edSigner, err := ssh.NewSignerFromKey(edKey)
+	require.NoError(t, err)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaSigner, err := ssh.NewSignerFromKey(rsaKey)
+	require.NoError(t, err)
+
+	// the server offers both keys, only the ed25519 one is known
+	serverConfig := &ssh.ServerConfig{NoClientAuth: true}
+	serverConfig.AddHostKey(edSigner)
+	serverConfig.AddHostKey(rsaSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				_, chans, reqs, err := ssh.NewServerConn(conn, serverConfig)
+				if err != nil {
+					return
+				}
+				go ssh.DiscardRequests(reqs)
+				for ch := range chans {
+					ch.Reject(ssh.Prohibited, "") //nolint:errcheck
+				}
+			}()
+		}
+	}()
+
+	addr := listener.Addr().String()
+	knownHosts := filepath.Join(t.TempDir(), "known_hosts")
+	lines := knownhosts.Line([]string{knownhosts.Normalize(addr)}, edSigner.PublicKey()) + "\n" +
+		knownhosts.Line([]string{"rsa.example.com"}, rsaSigner.PublicKey()) + "\n"
+	require.NoError(t, os.WriteFile(knownHosts, []byte(lines), 0o600))
+
+	checker := &HostKeyChecker{Files: []string{knownHosts}}
+
+	require.Nil(t, checker.Algorithms("ssh.cluster000.hosting.ovh.net:22"), "no algorithm for unknown hosts")
+	require.ElementsMatch(t, []string{ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256, ssh.KeyAlgoRSA}, checker.Algorithms("rsa.example.com:22"))
+
+	algorithms := checker.Algorithms(addr)
+	require.Equal(t, []string{ssh.KeyAlgoED25519}, algorithms)
+
+	_, err = ssh.Dial("tcp", addr, &ssh.ClientConfig{HostKeyCallback: checker.Callback()})
+	require.ErrorContains(t, err, ErrHostKeyMismatch.Error(), "the RSA key is negotiated by default")
+
+	client, err := ssh.Dial("tcp", addr, &ssh.ClientConfig{HostKeyCallback: checker.Callback(), HostKeyAlgorithms: algorithms})
+	require.NoError(t, err, "the known ed25519 key must be negotiated")
+	client.Close()
+}
@@ -0,0 +1,193 @@
+package remote
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/sftp"
+	"go.mlcdf.fr/sally/logging"
+	"golang.org/x/xerrors"
+)
+
+// ManifestName is the name of the manifest written at the root of the remote
+// folder on each sync.
+const ManifestName = ".owh-manifest.json"
+
+const manifestVersion = 1
+
+// maxCommandLength bounds the length of a sha256sum command line, well below
+// the usual ARG_MAX.
+const maxCommandLength = 100_000
+
+// ManifestEntry describes a remote file as it was uploaded.
+type ManifestEntry struct {
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"mtime"`
+	SHA256  string `json:"sha256"`
+}
+
+// Manifest records the content of a remote folder so the next sync can tell
+// which files changed without reading them back.
+type Manifest struct {
+	Version int                      `json:"version"`
+	Files   map[string]ManifestEntry `json:"files"`
+	// Checksum is the SHA-256 of Files. It is used to detect manifests that
+	// were edited by hand.
+	Checksum string `json:"checksum"`
+}
+
+func NewManifest() *Manifest {
+	return &Manifest{Version: manifestVersion, Files: map[string]ManifestEntry{}}
+}
+
+func (m *Manifest) checksum() (string, error) {
+	b, err := json.Marshal(m.Files)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Lookup returns the entry of relpath if it still describes the remote file,
+// i.e. the file wasn't modified on the hosting since it was uploaded.
+func (m *Manifest) Lookup(relpath string, remotefile os.FileInfo) (ManifestEntry, bool) {
+	if m == nil {
+		return ManifestEntry{}, false
+	}
+
+	entry, ok := m.Files[relpath]
+	if !ok || entry.Size != remotefile.Size() || entry.ModTime != remotefile.ModTime().Unix() {
+		return ManifestEntry{}, false
+	}
+
+	return entry, true
+}
+
+// readManifest returns the manifest of dest, or nil when it's missing or
+// can't be trusted.
+func readManifest(client *sftp.Client, dest string) *Manifest {
+	location := filepath.Join(dest, ManifestName)
+
+	f, err := client.Open(location)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logging.Debugf("failed to open %s: %s", location, err)
+		}
+		return nil
+	}
+	defer f.Close()
+
+	var manifest Manifest
+	if err := json.NewDecoder(f).Decode(&manifest); err != nil {
+		logging.Debugf("ignoring invalid manifest %s: %s", location, err)
+		return nil
+	}
+
+	if manifest.Version != manifestVersion {
+		logging.Debugf("ignoring manifest %s with version %d", location, manifest.Version)
+		return nil
+	}
+
+	checksum, err := manifest.checksum()
+	if err != nil || checksum != manifest.Checksum {
+		logging.Debugf("ignoring manifest %s: checksum mismatch, it was probably edited by hand", location)
+		return nil
+	}
+
+	return &manifest
+}
+
+func writeManifest(client *sftp.Client, dest string, manifest *Manifest) error {
+	location := filepath.Join(dest, ManifestName)
+
+	checksum, err := manifest.checksum()
+	if err != nil {
+		return err
+	}
+	manifest.Checksum = checksum
+
+	b, err := json.Marshal(manifest)
+	if err != nil {
+		return xerrors.Errorf("failed to encode manifest: %w", err)
+	}
+
+	f, err := client.Create(location)
+	if err != nil {
+		return xerrors.Errorf("error creating %s: %w", location, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(b); err != nil {
+		return xerrors.Errorf("error writing %s: %w", location, err)
+	}
+
+	return nil
+}
+
+// RemoteHashes computes the SHA-256 of the remote paths by running sha256sum
+// on the hosting, so the files don't have to be downloaded.
+func (c *Client) RemoteHashes(paths []string) (map[string]string, error) {
+	hashes := make(map[string]string, len(paths))
+
+	for len(paths) > 0 {
+		var cmd strings.Builder
+		cmd.WriteString("sha256sum --")
+
+		n := 0
+		for n < len(paths) && (n == 0 || cmd.Len()+len(paths[n])+3 < maxCommandLength) {
+			cmd.WriteString(" " + shellQuote(paths[n]))
+			n++
+		}
+
+		output, err := c.Run(cmd.String())
+		if err != nil {
+			return nil, xerrors.Errorf("failed to hash remote files: %w", err)
+		}
+
+		scanner := bufio.NewScanner(strings.NewReader(output))
+		for scanner.Scan() {
+			line := scanner.Text()
+
+			// sha256sum escapes names containing a backslash or a newline,
+			// these files are compared the slow way.
+			if strings.HasPrefix(line, "\\") {
+				continue
+			}
+
+			hash, path, found := strings.Cut(line, "  ")
+			if !found {
+				continue
+			}
+
+			hashes[path] = hash
+		}
+
+		paths = paths[n:]
+	}
+
+	return hashes, nil
+}
+
+func localHash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", xerrors.Errorf("error opening file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", xerrors.Errorf("error hashing %s: %w", path, err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,33 @@
+package remote
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifestLookup(t *testing.T) {
+	t.Parallel()
+
+	info, err := os.Stat("fixtures/www/index.html")
+	require.NoError(t, err)
+
+	manifest := NewManifest()
+	manifest.Files["index.html"] = ManifestEntry{Size: info.Size(), ModTime: info.ModTime().Unix(), SHA256: "abc"}
+	manifest.Files["stale.html"] = ManifestEntry{Size: info.Size(), ModTime: info.ModTime().Unix() - 1, SHA256: "abc"}
+
+	entry, ok := manifest.Lookup("index.html", info)
+	require.True(t, ok)
+	require.Equal(t, "abc", entry.SHA256)
+
+	_, ok = manifest.Lookup("stale.html", info)
+	require.False(t, ok, "an entry whose mtime differs from the remote file must not be trusted")
+
+	_, ok = manifest.Lookup("missing.html", info)
+	require.False(t, ok)
+
+	var nilManifest *Manifest
+	_, ok = nilManifest.Lookup("index.html", info)
+	require.False(t, ok)
+}
@@ -0,0 +1,141 @@
+package remote
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// PlanFile is a file Sync would upload or delete.
+type PlanFile struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// Plan lists the changes Sync has to make for the remote folder to mirror
+// the local one. Paths are relative to both folders.
+type Plan struct {
+	Add       []PlanFile `json:"add"`
+	Modify    []PlanFile `json:"modify"`
+	Delete    []PlanFile `json:"delete"`
+	Mkdir     []string   `json:"mkdir"`
+	Rmdir     []string   `json:"rmdir"`
+	Unchanged int        `json:"unchanged"`
+	// Shadowed lists the local paths not uploaded because they match a
+	// preserved remote path.
+	Shadowed []string `json:"shadowed"`
+
+	// UploadBytes is the number of bytes to upload.
+	UploadBytes int64 `json:"upload_bytes"`
+	// DeleteBytes is the number of bytes to delete.
+	DeleteBytes int64 `json:"delete_bytes"`
+}
+
+func newPlan() *Plan {
+	return &Plan{
+		Add:      []PlanFile{},
+		Modify:   []PlanFile{},
+		Delete:   []PlanFile{},
+		Mkdir:    []string{},
+		Rmdir:    []string{},
+		Shadowed: []string{},
+	}
+}
+
+// IsEmpty reports whether the remote folder already mirrors the local one.
+func (plan *Plan) IsEmpty() bool {
+	return len(plan.Add) == 0 && len(plan.Modify) == 0 && len(plan.Delete) == 0 &&
+		len(plan.Mkdir) == 0 && len(plan.Rmdir) == 0
+}
+
+func (plan *Plan) sort() {
+	for _, files := range [][]PlanFile{plan.Add, plan.Modify, plan.Delete} {
+		files := files
+		sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	}
+
+	sort.Strings(plan.Mkdir)
+	sort.Strings(plan.Rmdir)
+	sort.Strings(plan.Shadowed)
+
+	plan.UploadBytes = 0
+	for _, file := range append(plan.Add, plan.Modify...) {
+		plan.UploadBytes += file.Size
+	}
+
+	plan.DeleteBytes = 0
+	for _, file := range plan.Delete {
+		plan.DeleteBytes += file.Size
+	}
+}
+
+// removals returns the paths to remove for the plan to be applied: the
+// topmost directories of plan.Rmdir and the files of plan.Delete which are
+// not inside one of them.
+func (plan *Plan) removals() []string {
+	out := []string{}
+	dirs := []string{}
+
+	isInRemovedDir := func(path string) bool {
+		for _, dir := range dirs {
+			if strings.HasPrefix(path, dir+string(filepath.Separator)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, dir := range plan.Rmdir {
+		if isInRemovedDir(dir) {
+			continue
+		}
+		dirs = append(dirs, dir)
+		out = append(out, dir)
+	}
+
+	for _, file := range plan.Delete {
+		if isInRemovedDir(file.Path) {
+			continue
+		}
+		out = append(out, file.Path)
+	}
+
+	return out
+}
+
+// reverse returns the plan to apply for the local folder to mirror the
+// remote one.
+func (plan *Plan) reverse() *Plan {
+	reversed := &Plan{
+		Add:       plan.Delete,
+		Modify:    plan.Modify,
+		Delete:    plan.Add,
+		Mkdir:     plan.Rmdir,
+		Rmdir:     plan.Mkdir,
+		Unchanged: plan.Unchanged,
+		Shadowed:  plan.Shadowed,
+	}
+	reversed.sort()
+
+	return reversed
+}
+
+// conflicts returns the paths which are a file on one side and a directory on
+// the other.
+func (plan *Plan) conflicts() []string {
+	out := []string{}
+
+	files := map[string]bool{}
+	for _, file := range append(plan.Add, plan.Delete...) {
+		files[file.Path] = true
+	}
+
+	for _, dir := range append(plan.Mkdir, plan.Rmdir...) {
+		if files[dir] {
+			out = append(out, dir)
+		}
+	}
+
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,49 @@
+package remote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanRemovals(t *testing.T) {
+	t.Parallel()
+
+	plan := newPlan()
+	plan.Rmdir = []string{"assets", "assets/img", "old"}
+	plan.Delete = []PlanFile{
+		{Path: "assets/app.js", Size: 10},
+		{Path: "assets/img/logo.png", Size: 20},
+		{Path: "index.php", Size: 30},
+		{Path: "older.html", Size: 40},
+	}
+	plan.sort()
+
+	require.Equal(t, []string{"assets", "old", "index.php", "older.html"}, plan.removals())
+	require.Equal(t, int64(100), plan.DeleteBytes)
+}
+
+func TestPlanReverse(t *testing.T) {
+	t.Parallel()
+
+	plan := newPlan()
+	plan.Add = []PlanFile{{Path: "local.html", Size: 10}, {Path: "page", Size: 5}}
+	plan.Modify = []PlanFile{{Path: "index.html", Size: 20}}
+	plan.Delete = []PlanFile{{Path: "remote.html", Size: 30}, {Path: "blog", Size: 1}}
+	plan.Mkdir = []string{"blog"}
+	plan.Rmdir = []string{"page"}
+	plan.Unchanged = 3
+	plan.sort()
+
+	reversed := plan.reverse()
+
+	require.Equal(t, []PlanFile{{Path: "blog", Size: 1}, {Path: "remote.html", Size: 30}}, reversed.Add)
+	require.Equal(t, []PlanFile{{Path: "index.html", Size: 20}}, reversed.Modify)
+	require.Equal(t, []PlanFile{{Path: "local.html", Size: 10}, {Path: "page", Size: 5}}, reversed.Delete)
+	require.Equal(t, []string{"page"}, reversed.Mkdir)
+	require.Equal(t, []string{"blog"}, reversed.Rmdir)
+	require.Equal(t, 3, reversed.Unchanged)
+	require.Equal(t, int64(51), reversed.UploadBytes)
+
+	require.Equal(t, []string{"blog", "page"}, plan.conflicts())
+}
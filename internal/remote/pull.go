@@ -0,0 +1,190 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/sftp"
+	"go.mlcdf.fr/owh/internal/unit"
+	"golang.org/x/xerrors"
+)
+
+// PullOptions tunes Pull.
+type PullOptions struct {
+	SyncOptions
+	// Delete removes the local files missing from the remote folder.
+	Delete bool
+}
+
+// PullStats summarizes what Pull did.
+type PullStats struct {
+	Downloaded int64
+	Skipped    int64
+	Deleted    int64
+	Bytes      int64
+}
+
+func (stats *PullStats) String() string {
+	return fmt.Sprintf(
+		"%d downloaded, %d skipped, %d deleted, %s transferred",
+		stats.Downloaded,
+		stats.Skipped,
+		stats.Deleted,
+		unit.FormatBytes(stats.Bytes),
+	)
+}
+
+// Pull mirrors the remote folder src to the local folder dest, the reverse of
+// Sync: only the new or modified files are downloaded, opts.Concurrency at a
+// time. Local files missing from src are kept unless opts.Delete is set.
+func (c *Client) Pull(src string, dest string, opts PullOptions) (*PullStats, error) {
+	if src == "" {
+		return nil, ErrEmptyStringSrc
+	}
+
+	if dest == "" {
+		return nil, ErrEmptyStringDest
+	}
+
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return nil, xerrors.Errorf("error creating %s directory %w", dest, err)
+	}
+
+	// the plan of a sync from dest to src, whose changes are applied the
+	// other way around
+	s, err := c.newSyncer(dest, src, opts.SyncOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer s.close()
+
+	s.dest, err = resolveLink(s.client, src)
+	if err != nil {
+		return nil, err
+	}
+
+	if info, err := s.client.Stat(s.dest); err != nil || !info.IsDir() {
+		return nil, xerrors.Errorf("%s is not a directory on the hosting", src)
+	}
+
+	s.previous = readManifest(s.client, s.dest)
+
+	plan, err := s.plan()
+	if err != nil {
+		return nil, err
+	}
+
+	// A path which is a file on one side and a directory on the other is
+	// removed locally, then compared again
+	if conflicts := plan.conflicts(); len(conflicts) > 0 {
+		for _, relpath := range conflicts {
+			if err := os.RemoveAll(filepath.Join(dest, relpath)); err != nil {
+				return nil, err
+			}
+		}
+
+		s.identical = map[string]bool{}
+
+		plan, err = s.plan()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	stats := &PullStats{}
+
+	return stats, s.applyReversed(plan.reverse(), opts.Delete, stats)
+}
+
+// applyReversed applies the plan of a sync to the local folder: s.src is
+// the destination of the changes and s.dest their source.
+func (s *syncer) applyReversed(plan *Plan, deleteMissing bool, stats *PullStats) error {
+	if deleteMissing {
+		for _, relpath := range plan.removals() {
+			if err := os.RemoveAll(filepath.Join(s.src, relpath)); err != nil {
+				return err
+			}
+
+			stats.Deleted++
+		}
+	}
+
+	for _, relpath := range plan.Mkdir {
+		localpath := filepath.Join(s.src, relpath)
+
+		if err := os.MkdirAll(localpath, 0755); err != nil {
+			return xerrors.Errorf("error while mkdir %s: %w", localpath, err)
+		}
+	}
+
+	stats.Skipped = int64(plan.Unchanged)
+
+	group, _ := s.pool.GroupContext(context.Background())
+
+	for _, file := range append(plan.Add, plan.Modify...) {
+		remotepath := filepath.Join(s.dest, file.Path)
+		localpath := filepath.Join(s.src, file.Path)
+
+		group.Submit(func() error {
+			written, err := download(s.client, remotepath, localpath)
+			atomic.AddInt64(&stats.Bytes, written)
+			if err != nil {
+				return err
+			}
+
+			atomic.AddInt64(&stats.Downloaded, 1)
+			return nil
+		})
+	}
+
+	return group.Wait()
+}
+
+// download copies the remote file to local. It's written next to local
+// first, then moved in place, with the modification time of the remote file.
+func download(client *sftp.Client, remote string, local string) (int64, error) {
+	src, err := client.Open(remote)
+	if err != nil {
+		return 0, xerrors.Errorf("error opening %s: %w", remote, err)
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return 0, xerrors.Errorf("error while stat %s: %w", remote, err)
+	}
+
+	part := filepath.Join(filepath.Dir(local), "."+filepath.Base(local)+partSuffix)
+
+	dst, err := os.OpenFile(part, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return 0, err
+	}
+
+	written, err := io.Copy(dst, src)
+	if err != nil {
+		dst.Close()
+		os.Remove(part)
+		return written, xerrors.Errorf("error downloading %s: %w", remote, err)
+	}
+
+	if err := dst.Close(); err != nil {
+		os.Remove(part)
+		return written, err
+	}
+
+	if err := os.Chtimes(part, time.Now(), info.ModTime()); err != nil {
+		return written, err
+	}
+
+	if err := os.Rename(part, local); err != nil {
+		return written, xerrors.Errorf("error moving %s to %s: %w", part, local, err)
+	}
+
+	return written, nil
+}
@@ -0,0 +1,43 @@
+package remote
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownload(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	client := newPipeClient(t)
+
+	remote := filepath.Join(dir, "remote.html")
+	local := filepath.Join(dir, "local.html")
+
+	require.NoError(t, os.WriteFile(remote, []byte("<h1>live</h1>"), 0644))
+	require.NoError(t, os.WriteFile(local, []byte("<h1>stale content</h1>"), 0644))
+
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, os.Chtimes(remote, mtime, mtime))
+
+	written, err := download(client, remote, local)
+	require.NoError(t, err)
+	require.Equal(t, int64(13), written)
+
+	content, err := os.ReadFile(local)
+	require.NoError(t, err)
+	require.Equal(t, "<h1>live</h1>", string(content))
+
+	info, err := os.Stat(local)
+	require.NoError(t, err)
+	require.True(t, info.ModTime().Equal(mtime))
+
+	// the temporary file is moved in place
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+}
@@ -0,0 +1,157 @@
+package remote
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// Push uploads the given paths of src, relative to it, to dest and removes
+// from dest the ones missing from src. It's a Sync limited to these paths:
+// neither dest nor the rest of src are walked. The manifest of dest is
+// updated rather than replaced.
+func (c *Client) Push(src string, dest string, paths []string, opts SyncOptions) (*SyncStats, error) {
+	if src == "" {
+		return nil, ErrEmptyStringSrc
+	}
+
+	if dest == "" {
+		return nil, ErrEmptyStringDest
+	}
+
+	s, err := c.newSyncer(src, dest, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer s.close()
+
+	s.dest, err = resolveLink(s.client, dest)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.client.MkdirAll(s.partsDir); err != nil {
+		return nil, xerrors.Errorf("error creating %s directory %w", s.partsDir, err)
+	}
+
+	plan, err := s.planPaths(paths)
+	if err != nil {
+		return s.stats, err
+	}
+
+	s.stats.Plan = plan
+
+	if previous := readManifest(s.client, s.dest); previous != nil {
+		s.manifest = previous
+	}
+
+	for _, relpath := range plan.removals() {
+		for path := range s.manifest.Files {
+			if path == relpath || strings.HasPrefix(path, relpath+string(filepath.Separator)) {
+				delete(s.manifest.Files, path)
+			}
+		}
+	}
+
+	if err := s.apply(plan); err != nil {
+		return s.stats, err
+	}
+
+	return s.stats, writeManifest(s.client, s.dest, s.manifest)
+}
+
+// planPaths lists the changes to push for the given paths: the files are
+// uploaded, the directories created with their content and the missing
+// paths deleted. The remote files aren't compared.
+func (s *syncer) planPaths(paths []string) (*Plan, error) {
+	plan := newPlan()
+	seen := map[string]bool{}
+
+	add := func(relpath string, info os.FileInfo) {
+		if relpath == "." || seen[relpath] {
+			return
+		}
+		seen[relpath] = true
+
+		if info.IsDir() {
+			plan.Mkdir = append(plan.Mkdir, relpath)
+		} else {
+			plan.Add = append(plan.Add, PlanFile{Path: relpath, Size: info.Size()})
+		}
+	}
+
+	for _, relpath := range paths {
+		localpath := filepath.Join(s.src, relpath)
+
+		info, err := os.Stat(localpath)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				return nil, xerrors.Errorf("error while stat %s: %w", localpath, err)
+			}
+
+			// Whether it was a directory is unknown, it's kept if it
+			// matches either way
+			if !s.skipped(relpath, false) && !s.skipped(relpath, true) {
+				plan.Delete = append(plan.Delete, PlanFile{Path: relpath})
+			}
+			continue
+		}
+
+		if s.skipped(relpath, info.IsDir()) {
+			continue
+		}
+
+		// the parent directory may be new too
+		if parent := filepath.Dir(relpath); parent != "." && !seen[parent] {
+			seen[parent] = true
+			plan.Mkdir = append(plan.Mkdir, parent)
+		}
+
+		if !info.IsDir() {
+			add(relpath, info)
+			continue
+		}
+
+		err = filepath.WalkDir(localpath, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return xerrors.Errorf("error walking %s: %w", path, err)
+			}
+
+			relpath, err := filepath.Rel(s.src, path)
+			if err != nil {
+				return err
+			}
+
+			info, err := os.Stat(path)
+			if err != nil {
+				return xerrors.Errorf("error while stat %s: %w", path, err)
+			}
+
+			if s.skipped(relpath, info.IsDir()) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			add(relpath, info)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	plan.sort()
+
+	return plan, nil
+}
+
+// skipped reports whether relpath is ignored or preserved, and so must not
+// be pushed.
+func (s *syncer) skipped(relpath string, isDir bool) bool {
+	return skipFile(s.ignore, relpath, isDir) || s.preserve.Match(relpath, isDir)
+}
@@ -0,0 +1,32 @@
+package remote
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mlcdf.fr/owh/internal/ignore"
+)
+
+func TestPlanPaths(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "blog", "2023", "node_modules"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.html"), []byte("index"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "blog", "2023", "post.html"), []byte("post"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "blog", "2023", "node_modules", "x.js"), []byte("x"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".env"), []byte("SECRET=1"), 0644))
+
+	s := &syncer{src: dir, ignore: ignore.Default(), preserve: ignore.New("uploads/")}
+
+	plan, err := s.planPaths([]string{"index.html", "blog", "blog/2023/post.html", ".env", "old.html", "uploads", "index.html"})
+	require.NoError(t, err)
+
+	require.Equal(t, []PlanFile{{Path: "blog/2023/post.html", Size: 4}, {Path: "index.html", Size: 5}}, plan.Add)
+	require.Equal(t, []string{"blog", "blog/2023"}, plan.Mkdir)
+	// the preserved path is never deleted, even if it was a directory
+	require.Equal(t, []PlanFile{{Path: "old.html"}}, plan.Delete)
+}
@@ -0,0 +1,307 @@
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/sftp"
+	"go.mlcdf.fr/sally/logging"
+	"golang.org/x/xerrors"
+)
+
+// ReleasesDir is the directory, relative to the hosting home, under which the
+// releases of every website are uploaded.
+const ReleasesDir = ".owh/releases"
+
+// DefaultKeepReleases is the number of releases kept after a deployment.
+const DefaultKeepReleases = 5
+
+var ErrNoPreviousRelease = errors.New("no previous release to roll back to")
+var ErrReleaseNotFound = errors.New("release not found")
+
+// Release is a directory holding one uploaded version of a website.
+type Release struct {
+	ID      string
+	Path    string
+	Current bool
+}
+
+// ReleasesPath returns the directory holding the releases of dest.
+func ReleasesPath(dest string) string {
+	return filepath.Join(ReleasesDir, dest)
+}
+
+// ReleasePath returns the directory of the release id of dest.
+func ReleasePath(dest string, id string) string {
+	return filepath.Join(ReleasesPath(dest), id)
+}
+
+// NewReleaseID returns a release identifier that sorts chronologically, with
+// a millisecond precision so that deployments of the same second don't
+// collide.
+func NewReleaseID(t time.Time) string {
+	t = t.UTC()
+	return t.Format("20060102150405") + fmt.Sprintf("%03d", t.Nanosecond()/int(time.Millisecond))
+}
+
+// Releases lists the releases of dest, oldest first.
+func (c *Client) Releases(dest string) ([]Release, error) {
+	client, err := c.session()
+	if err != nil {
+		return nil, err
+	}
+
+	return releases(client, dest)
+}
+
+func releases(client *sftp.Client, dest string) ([]Release, error) {
+	entries, err := client.ReadDir(ReleasesPath(dest))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []Release{}, nil
+		}
+		return nil, xerrors.Errorf("error listing releases of %s: %w", dest, err)
+	}
+
+	current, err := currentRelease(client, dest)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]Release, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		out = append(out, Release{
+			ID:      entry.Name(),
+			Path:    ReleasePath(dest, entry.Name()),
+			Current: entry.Name() == current,
+		})
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+
+	return out, nil
+}
+
+// currentRelease returns the id of the release dest points to, or an empty
+// string when dest is not a symlink to a release.
+func currentRelease(client *sftp.Client, dest string) (string, error) {
+	info, err := client.Lstat(dest)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", xerrors.Errorf("error while stat %s: %w", dest, err)
+	}
+
+	if info.Mode()&os.ModeSymlink == 0 {
+		return "", nil
+	}
+
+	target, err := client.ReadLink(dest)
+	if err != nil {
+		return "", xerrors.Errorf("error reading link %s: %w", dest, err)
+	}
+
+	if filepath.Base(filepath.Dir(target)) != filepath.Base(dest) {
+		return "", nil
+	}
+
+	return filepath.Base(target), nil
+}
+
+// resolveLink returns the path dest points to when it's a symlink, so the
+// live release of a website can be walked.
+func resolveLink(client *sftp.Client, dest string) (string, error) {
+	info, err := client.Lstat(dest)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return dest, nil
+	}
+
+	target, err := client.ReadLink(dest)
+	if err != nil {
+		return "", xerrors.Errorf("error reading link %s: %w", dest, err)
+	}
+
+	if filepath.IsAbs(target) {
+		return target, nil
+	}
+
+	return filepath.Join(filepath.Dir(dest), target), nil
+}
+
+// NewRelease creates the directory of a new release of dest and returns its
+// id. The release is seeded with a server-side copy of the live website so
+// only the changed files need to be uploaded by Sync, and the remote-only
+// content, such as the preserved paths, is kept. It fails when the copy
+// fails, rather than activating a release missing that content.
+//
+// The files written to the live website between the copy and the switch to
+// the release, such as uploads, aren't part of the release.
+func (c *Client) NewRelease(dest string) (string, error) {
+	client, err := c.session()
+	if err != nil {
+		return "", err
+	}
+
+	id := NewReleaseID(time.Now())
+	path := ReleasePath(dest, id)
+
+	if _, err := client.Lstat(path); err == nil {
+		return "", xerrors.Errorf("release %s already exists", id)
+	}
+
+	if err := client.MkdirAll(ReleasesPath(dest)); err != nil {
+		return "", xerrors.Errorf("error creating %s directory %w", ReleasesPath(dest), err)
+	}
+
+	if info, err := client.Stat(dest); err == nil && info.IsDir() {
+		_, err := c.Run(fmt.Sprintf("cp -a %s %s", shellQuote(dest+"/"), shellQuote(path)))
+		if err == nil {
+			return id, nil
+		}
+
+		if err := forceRemove(client, path); err != nil {
+			logging.Debugf("failed to clean up release %s: %s", id, err)
+		}
+		return "", xerrors.Errorf("error copying %s to release %s: %w", dest, id, err)
+	}
+
+	if err := client.MkdirAll(path); err != nil {
+		return "", xerrors.Errorf("error creating %s directory %w", path, err)
+	}
+
+	return id, nil
+}
+
+// Activate atomically points dest to the release id. When dest is still a
+// plain directory (a website deployed before releases existed), it is first
+// moved to a release of its own so it can be rolled back to.
+func (c *Client) Activate(dest string, id string) error {
+	client, err := c.session()
+	if err != nil {
+		return err
+	}
+
+	path := ReleasePath(dest, id)
+	if info, err := client.Stat(path); err != nil || !info.IsDir() {
+		return xerrors.Errorf("%w: %s", ErrReleaseNotFound, id)
+	}
+
+	target, err := filepath.Rel(filepath.Dir(dest), path)
+	if err != nil {
+		return err
+	}
+
+	tmp := dest + ".owh-tmp"
+	_ = client.Remove(tmp)
+
+	// the symlink is ready before the legacy directory is moved, so the
+	// website is only missing between the two renames
+	if err := client.Symlink(target, tmp); err != nil {
+		return xerrors.Errorf("error creating symlink %s: %w", tmp, err)
+	}
+
+	info, err := client.Lstat(dest)
+	if err == nil && info.Mode()&os.ModeSymlink == 0 {
+		legacy := ReleasePath(dest, NewReleaseID(info.ModTime()))
+		if err := client.Rename(dest, legacy); err != nil {
+			_ = client.Remove(tmp)
+			return xerrors.Errorf("error moving %s to %s: %w", dest, legacy, err)
+		}
+	}
+
+	// rename(2) replaces the previous symlink in one step
+	if err := client.PosixRename(tmp, dest); err != nil {
+		_ = client.Remove(tmp)
+		return xerrors.Errorf("error switching %s to release %s: %w", dest, id, err)
+	}
+
+	return nil
+}
+
+// Rollback points dest to the release id, or to the release preceding the
+// current one when id is empty. It returns the id of the activated release.
+func (c *Client) Rollback(dest string, id string) (string, error) {
+	list, err := c.Releases(dest)
+	if err != nil {
+		return "", err
+	}
+
+	if id == "" {
+		id, err = previousRelease(list)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return id, c.Activate(dest, id)
+}
+
+func previousRelease(list []Release) (string, error) {
+	for i, release := range list {
+		if release.Current {
+			if i == 0 {
+				return "", ErrNoPreviousRelease
+			}
+			return list[i-1].ID, nil
+		}
+	}
+
+	return "", ErrNoPreviousRelease
+}
+
+// PruneReleases removes the oldest releases of dest, keeping the keep most
+// recent ones and always the current one. It returns the removed ids.
+func (c *Client) PruneReleases(dest string, keep int) ([]string, error) {
+	list, err := c.Releases(dest)
+	if err != nil {
+		return nil, err
+	}
+
+	removed := []string{}
+
+	for _, release := range releasesToPrune(list, keep) {
+		if err := c.ForceRemove(release.Path); err != nil {
+			return removed, err
+		}
+		removed = append(removed, release.ID)
+	}
+
+	return removed, nil
+}
+
+func releasesToPrune(list []Release, keep int) []Release {
+	out := []Release{}
+
+	if keep < 1 {
+		keep = 1
+	}
+
+	for i, release := range list {
+		if release.Current || i >= len(list)-keep {
+			continue
+		}
+		out = append(out, release)
+	}
+
+	return out
+}
+
+// RemoveReleases deletes every release of dest.
+func (c *Client) RemoveReleases(dest string) error {
+	return c.ForceRemove(ReleasesPath(dest))
+}
+
+// shellQuote quotes str so it is passed as a single argument to a POSIX shell.
+func shellQuote(str string) string {
+	return "'" + strings.ReplaceAll(str, "'", `'\''`) + "'"
+}
@@ -0,0 +1,76 @@
+package remote
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviousRelease(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		list    []Release
+		want    string
+		wantErr error
+	}{
+		{
+			name:    "no release",
+			list:    []Release{},
+			wantErr: ErrNoPreviousRelease,
+		},
+		{
+			name:    "current is the oldest",
+			list:    []Release{{ID: "1", Current: true}, {ID: "2"}},
+			wantErr: ErrNoPreviousRelease,
+		},
+		{
+			name: "current is the latest",
+			list: []Release{{ID: "1"}, {ID: "2"}, {ID: "3", Current: true}},
+			want: "2",
+		},
+		{
+			name: "already rolled back",
+			list: []Release{{ID: "1"}, {ID: "2", Current: true}, {ID: "3"}},
+			want: "1",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := previousRelease(test.list)
+			require.ErrorIs(t, err, test.wantErr)
+			require.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestReleasesToPrune(t *testing.T) {
+	t.Parallel()
+
+	list := []Release{{ID: "1"}, {ID: "2", Current: true}, {ID: "3"}, {ID: "4"}, {ID: "5"}}
+
+	got := releasesToPrune(list, 2)
+
+	require.Equal(t, []Release{{ID: "1"}, {ID: "3"}}, got)
+}
+
+func TestNewReleaseID(t *testing.T) {
+	t.Parallel()
+
+	second := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	first := NewReleaseID(second)
+	next := NewReleaseID(second.Add(250 * time.Millisecond))
+
+	require.Equal(t, "20230102030405000", first)
+	require.Equal(t, "20230102030405250", next)
+	require.Less(t, first, next, "releases of the same second must differ and sort chronologically")
+	require.Less(t, "20230102030404", first, "ids of the previous format must sort before")
+}
@@ -0,0 +1,93 @@
+package remote
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/xerrors"
+)
+
+// ErrUnsafePath is returned when asked to remove a path that isn't strictly
+// inside the home of the user.
+var ErrUnsafePath = errors.New("refusing to remove a path outside of the home directory")
+
+// ForceRemove removes dest and everything it contains, like rm -rf. dest must
+// be inside the home of the user, which can't be removed itself.
+func (c *Client) ForceRemove(dest string) error {
+	client, err := c.session()
+	if err != nil {
+		return err
+	}
+
+	return forceRemove(client, dest)
+}
+
+func forceRemove(client *sftp.Client, dest string) error {
+	home, err := client.Getwd()
+	if err != nil {
+		return xerrors.Errorf("failed to get the home directory: %w", err)
+	}
+
+	path, err := safePath(home, dest)
+	if err != nil {
+		return err
+	}
+
+	if err := removeAll(client, path); err != nil {
+		return xerrors.Errorf("failed to force remove %s: %w", dest, err)
+	}
+
+	return nil
+}
+
+// safePath cleans dest, relative to home unless absolute, and returns it if it
+// points strictly inside home.
+func safePath(home string, dest string) (string, error) {
+	if strings.TrimSpace(dest) == "" || strings.HasPrefix(dest, "~") {
+		return "", xerrors.Errorf("%w: %q", ErrUnsafePath, dest)
+	}
+
+	path := filepath.Clean(dest)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(home, path)
+	}
+
+	rel, err := filepath.Rel(filepath.Clean(home), path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", xerrors.Errorf("%w: %q", ErrUnsafePath, dest)
+	}
+
+	return path, nil
+}
+
+// removeAll removes path and its content. Symbolic links are removed, not
+// followed. A path that doesn't exist is not an error.
+func removeAll(client *sftp.Client, path string) error {
+	info, err := client.Lstat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	if !info.IsDir() {
+		return client.Remove(path)
+	}
+
+	entries, err := client.ReadDir(path)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if err := removeAll(client, filepath.Join(path, entry.Name())); err != nil {
+			return err
+		}
+	}
+
+	return client.RemoveDirectory(path)
+}
@@ -0,0 +1,89 @@
+package remote
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSafePath(t *testing.T) {
+	t.Parallel()
+
+	home := "/home/user"
+
+	testCases := []struct {
+		dest string
+		want string
+	}{
+		{dest: "www", want: "/home/user/www"},
+		{dest: "./www/my site/", want: "/home/user/www/my site"},
+		{dest: "/home/user/www", want: "/home/user/www"},
+		{dest: "www/../blog", want: "/home/user/blog"},
+		{dest: ""},
+		{dest: " "},
+		{dest: "."},
+		{dest: "./"},
+		{dest: "~"},
+		{dest: "~/www"},
+		{dest: "/"},
+		{dest: "/home/user"},
+		{dest: "/home/user2"},
+		{dest: ".."},
+		{dest: "../other"},
+		{dest: "www/../.."},
+	}
+
+	for _, test := range testCases {
+		test := test
+
+		t.Run(test.dest, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := safePath(home, test.dest)
+
+			if test.want == "" {
+				if !errors.Is(err, ErrUnsafePath) {
+					t.Errorf("want ErrUnsafePath for %q, got %s, %v", test.dest, got, err)
+				}
+				return
+			}
+
+			require.NoError(t, err)
+			if got != test.want {
+				t.Errorf("want %s, got %s", test.want, got)
+			}
+		})
+	}
+}
+
+func TestRemoveAll(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	client := newPipeClient(t)
+
+	outside := filepath.Join(dir, "outside")
+	require.NoError(t, os.MkdirAll(outside, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "keep.txt"), []byte("keep"), 0644))
+
+	root := filepath.Join(dir, "site")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "a", "b"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "index.html"), []byte("index"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "a", "b", "c.txt"), []byte("c"), 0644))
+	require.NoError(t, os.Symlink(outside, filepath.Join(root, "link")))
+
+	require.NoError(t, removeAll(client, root))
+
+	_, err := os.Lstat(root)
+	require.True(t, os.IsNotExist(err))
+
+	// the target of the symbolic link is left untouched
+	_, err = os.Stat(filepath.Join(outside, "keep.txt"))
+	require.NoError(t, err)
+
+	// like rm -rf, a missing path is not an error
+	require.NoError(t, removeAll(client, root))
+}
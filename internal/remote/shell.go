@@ -0,0 +1,91 @@
+package remote
+
+import (
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/xerrors"
+)
+
+// TerminalSize is the size of a terminal, in characters.
+type TerminalSize struct {
+	Width  int
+	Height int
+}
+
+// Terminal describes the local terminal a remote session is attached to.
+type Terminal struct {
+	// Term is the value of the TERM environment variable.
+	Term string
+	Size TerminalSize
+	// Resize receives the new sizes of the terminal, if any.
+	Resize <-chan TerminalSize
+}
+
+// Shell runs cmd, or the login shell of the user when cmd is empty, wired to
+// stdin, stdout and stderr. A pseudo terminal is allocated when term is not
+// nil. It returns the exit code of the remote command.
+func (c *Client) Shell(cmd string, term *Terminal, stdin io.Reader, stdout io.Writer, stderr io.Writer) (int, error) {
+	session, err := c.conn.NewSession()
+	if err != nil {
+		return -1, xerrors.Errorf("failed to open session: %w", err)
+	}
+	defer session.Close()
+
+	session.Stdin = stdin
+	session.Stdout = stdout
+	session.Stderr = stderr
+
+	if term != nil {
+		modes := ssh.TerminalModes{
+			ssh.ECHO:          1,
+			ssh.TTY_OP_ISPEED: 14400,
+			ssh.TTY_OP_OSPEED: 14400,
+		}
+
+		if err := session.RequestPty(term.Term, term.Size.Height, term.Size.Width, modes); err != nil {
+			return -1, xerrors.Errorf("failed to request a pseudo terminal: %w", err)
+		}
+
+		if term.Resize != nil {
+			go func() {
+				for size := range term.Resize {
+					_ = session.WindowChange(size.Height, size.Width)
+				}
+			}()
+		}
+	}
+
+	if cmd == "" {
+		err = session.Shell()
+	} else {
+		err = session.Start(cmd)
+	}
+
+	if err != nil {
+		return -1, xerrors.Errorf("failed to start session: %w", err)
+	}
+
+	return exitCode(session.Wait())
+}
+
+// exitCode returns the exit code of a remote command from the error returned
+// by its session.
+func exitCode(err error) (int, error) {
+	if err == nil {
+		return 0, nil
+	}
+
+	var exitErr *ssh.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitStatus(), nil
+	}
+
+	var missingErr *ssh.ExitMissingError
+	if errors.As(err, &missingErr) {
+		return -1, xerrors.Errorf("remote command exited without exit status")
+	}
+
+	return -1, err
+}
@@ -2,6 +2,8 @@ package remote
 
 import (
 	"testing"
+
+	"go.mlcdf.fr/owh/internal/ignore"
 )
 
 func TestSkipFile(t *testing.T) {
@@ -32,18 +34,35 @@ func TestSkipFile(t *testing.T) {
 			path: ".htaccess",
 			want: false,
 		},
+		{
+			name: ".ovhconfig file",
+			path: ".ovhconfig",
+			want: false,
+		},
 		{
 			name: "file instead node_modules",
 			path: "node_modules/yolo/index.js",
 			want: true,
 		},
+		{
+			name: "folder name containing node_modules",
+			path: "my.node_modules_notes/index.html",
+			want: false,
+		},
+		{
+			name: "file inside __pycache__",
+			path: "app/__pycache__/main.pyc",
+			want: true,
+		},
 	}
 
 	for _, test := range testCases {
+		test := test
+
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
 
-			if got := skipFile(test.path); got != test.want {
+			if got := skipFile(ignore.Default(), test.path, false); got != test.want {
 				t.Errorf("want skipFile=%t, got %t for %s", got, test.want, test.path)
 			}
 		})
@@ -1,19 +1,20 @@
 package remote
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
-	"io/fs"
-	"log"
+	"math/rand"
+	"net"
 	"os"
-	"path/filepath"
-	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/sftp"
+	"go.mlcdf.fr/owh/internal/ignore"
 	"go.mlcdf.fr/sally/logging"
 	"golang.org/x/crypto/ssh"
 	"golang.org/x/xerrors"
@@ -23,7 +24,15 @@ var ErrEmptyStringSrc = errors.New("src cannot be an empty string")
 var ErrEmptyStringDest = errors.New("dest cannot be an empty string")
 
 type Client struct {
-	conn *ssh.Client
+	conn   *ssh.Client
+	config *Config
+
+	// mu guards the sftp session shared by the methods of the client and the
+	// state of the connection.
+	mu     sync.Mutex
+	sftp   *sftp.Client
+	closed bool
+	done   chan struct{}
 }
 
 type Config struct {
@@ -33,196 +42,281 @@ type Config struct {
 	SSHConfig *ssh.ClientConfig
 }
 
-type ConfigFactory func(host string, port int, user string, password string) *Config
+type ConfigFactory func(host string, port int, user string, password string, hostKeyCallback ssh.HostKeyCallback) *Config
 
 // PasswordConfig returns a password based ssh.ClientConfig.
-func NewPasswordConfig(host string, port int, user string, password string) *Config {
-	return &Config{
-		Host: host,
-		Port: port,
-		SSHConfig: &ssh.ClientConfig{
-			Config:          ssh.Config{},
-			User:            user,
-			Auth:            []ssh.AuthMethod{ssh.RetryableAuthMethod(ssh.Password(password), 5)},
-			HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-			Timeout:         10 * time.Second,
-		},
-	}
+func NewPasswordConfig(host string, port int, user string, password string, hostKeyCallback ssh.HostKeyCallback) *Config {
+	auth := []ssh.AuthMethod{ssh.RetryableAuthMethod(ssh.Password(password), 5)}
+	return NewConfig(host, port, user, auth, hostKeyCallback)
 }
 
 var _ ConfigFactory = NewPasswordConfig
 
-func Connect(config *Config) (*Client, error) {
-	client := &Client{}
+const (
+	maxDialAttempts = 5
+	dialBackoff     = 500 * time.Millisecond
+	maxDialBackoff  = 8 * time.Second
+
+	// KeepAliveInterval is the interval at which the server is pinged so the
+	// connection isn't dropped while idle.
+	KeepAliveInterval = 30 * time.Second
+)
+
+// Connect dials the hosting. Failed attempts are retried with an exponential
+// backoff until ctx is done.
+func Connect(ctx context.Context, config *Config) (*Client, error) {
+	conn, err := dial(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Client{conn: conn, config: config}
+	c.keepAlive()
+
+	return c, nil
+}
 
+func dial(ctx context.Context, config *Config) (*ssh.Client, error) {
 	var err error
-	var retry int
 
-	for retry < 5 {
-		client.conn, err = ssh.Dial("tcp", fmt.Sprintf("%s:%d", config.Host, config.Port), config.SSHConfig)
+	// ssh.Dial doesn't wrap the error of the host key check, it's kept to
+	// be returned as is.
+	var hostKeyErr error
+
+	sshConfig := *config.SSHConfig
+	if callback := sshConfig.HostKeyCallback; callback != nil {
+		sshConfig.HostKeyCallback = func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			hostKeyErr = callback(hostname, remote, key)
+			return hostKeyErr
+		}
+	}
+
+	addr := fmt.Sprintf("%s:%d", config.Host, config.Port)
+
+	for attempt := 0; attempt < maxDialAttempts; attempt++ {
+		if attempt > 0 {
+			delay := backoff(attempt)
+			logging.Debugf("failed to connect to %s, retrying in %s: %s", addr, delay, err)
+
+			select {
+			case <-ctx.Done():
+				return nil, xerrors.Errorf("failed to connect to %s: %w", addr, ctx.Err())
+			case <-time.After(delay):
+			}
+		}
+
+		var conn *ssh.Client
+
+		conn, err = dialContext(ctx, addr, &sshConfig)
 		if err == nil {
-			return client, nil
+			return conn, nil
+		}
+
+		// a rejected host key won't be accepted by retrying
+		if hostKeyErr != nil {
+			return nil, hostKeyErr
 		}
 
-		retry++
-		time.Sleep(1 * time.Second)
+		if ctx.Err() != nil {
+			return nil, xerrors.Errorf("failed to connect to %s: %w", addr, ctx.Err())
+		}
 	}
 
 	return nil, err
 }
 
-func (c *Client) Sync(src string, dest string) error {
-	if src == "" {
-		return ErrEmptyStringSrc
-	}
+// dialContext is ssh.Dial, aborting the connection and the handshake when
+// ctx is done.
+func dialContext(ctx context.Context, addr string, config *ssh.ClientConfig) (*ssh.Client, error) {
+	dialer := net.Dialer{Timeout: config.Timeout}
 
-	if dest == "" {
-		return ErrEmptyStringDest
-	}
-
-	client, err := sftp.NewClient(c.conn)
-	if err != nil {
-		return xerrors.Errorf("error creating %s directory %w", dest, err)
-	}
-
-	err = client.MkdirAll(dest)
+	netConn, err := dialer.DialContext(ctx, "tcp", addr)
 	if err != nil {
-		return xerrors.Errorf("error creating %s directory %w", dest, err)
+		return nil, err
 	}
 
-	// Delete extra files
-	walker := client.Walk(dest)
-	for walker.Step() {
-		relpath, err := filepath.Rel(dest, walker.Path())
+	done := make(chan struct{})
+	defer close(done)
 
-		if err != nil {
-			return err
+	go func() {
+		select {
+		case <-ctx.Done():
+			netConn.Close()
+		case <-done:
 		}
+	}()
 
-		localpath := filepath.Join(src, relpath)
+	conn, chans, reqs, err := ssh.NewClientConn(netConn, addr, config)
+	if err != nil {
+		netConn.Close()
+		return nil, err
+	}
 
-		remotepath := filepath.Join(dest, relpath)
+	return ssh.NewClient(conn, chans, reqs), nil
+}
 
-		remotefile, err := client.Stat(remotepath)
-		if err != nil {
-			if err.Error() == "file does not exist" {
-				continue
-			}
-			return xerrors.Errorf("error while stat remote file %s: %w", remotepath, err)
-		}
+// backoff returns the delay before the given attempt: it doubles at each
+// attempt, up to maxDialBackoff, with a random jitter of up to a half.
+func backoff(attempt int) time.Duration {
+	delay := dialBackoff << (attempt - 1)
+	if delay > maxDialBackoff || delay <= 0 {
+		delay = maxDialBackoff
+	}
 
-		fmt.Println(localpath)
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
 
-		localfile, err := os.Stat(localpath)
-		if err != nil {
-			if os.IsNotExist(err) {
-				// The file is present on remote but not locally
-				err := c.ForceRemove(remotepath)
-				if err != nil {
-					return err
+// keepAlive pings the server every KeepAliveInterval until the connection is
+// closed. A connection that doesn't answer is closed.
+func (c *Client) keepAlive() {
+	c.mu.Lock()
+	conn := c.conn
+	done := make(chan struct{})
+	c.done = done
+	c.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(KeepAliveInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if _, _, err := conn.SendRequest("keepalive@openssh.com", true, nil); err != nil {
+					logging.Debugf("connection lost: %s", err)
+					conn.Close()
+
+					c.mu.Lock()
+					if c.conn == conn {
+						c.closed = true
+					}
+					c.mu.Unlock()
+					return
 				}
-				continue
 			}
-			return xerrors.Errorf("error while stat %s: %w", localpath, err)
 		}
+	}()
+}
 
-		// Both are directories
-		if localfile.IsDir() && remotefile.IsDir() {
-			continue
-		}
+// session returns the sftp session shared by the methods of the client,
+// opening it on first use.
+func (c *Client) session() (*sftp.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-		// Both are files
-		if !localfile.IsDir() && !remotefile.IsDir() {
-			if localfile.Size() == remotefile.Size() {
-				identical, err := isIdentical(client, localpath, remotepath)
-				if err != nil {
-					return err
-				}
+	if c.sftp != nil {
+		return c.sftp, nil
+	}
 
-				if identical {
-					continue
-				}
-			}
-		}
+	client, err := sftp.NewClient(c.conn, sftp.UseConcurrentWrites(true))
+	if err != nil {
+		return nil, xerrors.Errorf("error opening sftp session: %w", err)
+	}
 
-		logging.Debugf("%s", remotefile.IsDir())
-		// files not identical
-		// or one is a dir and the other is a file
-		err = c.ForceRemove(remotepath)
-		if err != nil {
-			return err
-		}
+	c.sftp = client
+	return client, nil
+}
+
+// Reconnect closes the connection and dials the hosting again. It must not
+// be called while other methods of the client are running.
+func (c *Client) Reconnect(ctx context.Context) error {
+	c.shutdown()
+
+	conn, err := dial(ctx, c.config)
+	if err != nil {
+		return xerrors.Errorf("failed to reconnect: %w", err)
 	}
 
-	// Create new files
-	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
-		if path == src {
-			return nil
-		}
+	c.mu.Lock()
+	c.conn = conn
+	c.closed = false
+	c.mu.Unlock()
 
-		if err != nil {
-			return xerrors.Errorf("error walking %s path %s: %w", src, path, err)
-		}
+	c.keepAlive()
+	return nil
+}
 
-		logging.Debugf("path: %s", path)
+// Closed reports whether the connection was closed, or lost.
+func (c *Client) Closed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-		if skipFile(path) {
-			logging.Debugf("Path %s skipped", path)
-			return nil
-		}
+	return c.closed
+}
 
-		relpath, err := filepath.Rel(src, path)
-		if err != nil {
-			return err
-		}
+// Close closes the connection to the hosting.
+func (c *Client) Close() error {
+	return c.shutdown()
+}
 
-		remotepath := filepath.Join(dest, relpath)
+func (c *Client) shutdown() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-		localfile, err := os.Stat(path)
-		if err != nil {
-			return xerrors.Errorf("error while stat %s: %w", path, err)
-		}
+	if c.done != nil {
+		close(c.done)
+		c.done = nil
+	}
 
-		if localfile.IsDir() {
-			if err := client.MkdirAll(remotepath); err != nil {
-				return xerrors.Errorf("error while mkdir %s on remote: %w", remotepath, err)
-			}
-			return nil
-		}
+	if c.sftp != nil {
+		c.sftp.Close()
+		c.sftp = nil
+	}
 
-		logging.Debugf(path)
+	c.closed = true
 
-		return createFile(client, path, remotepath)
-	})
+	if c.conn == nil {
+		return nil
+	}
+
+	return c.conn.Close()
 }
 
-func (c *Client) Run(cmd string) (string, error) {
-	session, err := c.conn.NewSession()
+// ErrFileTooLarge is returned by ReadFile for files larger than the limit.
+var ErrFileTooLarge = errors.New("file too large")
+
+// ReadFile returns the content of the remote file at path, if it's no more
+// than limit bytes.
+func (c *Client) ReadFile(path string, limit int64) ([]byte, error) {
+	client, err := c.session()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	defer session.Close()
 
-	output, err := session.CombinedOutput(cmd)
+	f, err := client.Open(path)
 	if err != nil {
-		log.Println(string(output))
-		return "", xerrors.Errorf("failed to run command %s: %w", cmd, err)
+		return nil, xerrors.Errorf("error opening %s: %w", path, err)
 	}
+	defer f.Close()
 
-	return string(output), nil
-}
-
-// ForceRemove performs a rm -rf of the dest.
-func (c *Client) ForceRemove(dest string) error {
-	_, err := c.Run(fmt.Sprintf("rm -rf %s", dest))
+	content, err := io.ReadAll(io.LimitReader(f, limit+1))
 	if err != nil {
-		return xerrors.Errorf("failed to force remove: %w", err)
+		return nil, xerrors.Errorf("error reading %s: %w", path, err)
 	}
-	return nil
+
+	if int64(len(content)) > limit {
+		return nil, xerrors.Errorf("%s: %w", path, ErrFileTooLarge)
+	}
+
+	return content, nil
+}
+
+// copyBuffers holds the buffers used to hash the files compared by
+// isIdentical, which runs for many files concurrently.
+var copyBuffers = sync.Pool{
+	New: func() any {
+		buffer := make([]byte, 32<<10)
+		return &buffer
+	},
 }
 
 func isIdentical(client *sftp.Client, path1, path2 string) (bool, error) {
-	buffer := make([]byte, 10_000_000)
+	buf := copyBuffers.Get().(*[]byte)
+	defer copyBuffers.Put(buf)
+
+	buffer := *buf
 	h1 := md5.New()
 	h2 := md5.New()
 
@@ -254,42 +348,8 @@ func isIdentical(client *sftp.Client, path1, path2 string) (bool, error) {
 	return false, nil
 }
 
-func createFile(client *sftp.Client, localpath, remotepath string) error {
-	remotef, err := client.Create(remotepath)
-	if err != nil {
-		return xerrors.Errorf("error creating %s: %w", remotepath, err)
-	}
-
-	localf, err := os.Open(localpath)
-	if err != nil {
-		return xerrors.Errorf("error opening %s: %w", localpath, err)
-	}
-
-	_, err = io.Copy(remotef, localf)
-	if err != nil {
-		return xerrors.Errorf("error copying %s to %s: %w", localpath, remotepath, err)
-	}
-
-	logging.Debugf(remotepath)
-
-	return nil
-}
-
-func skipFile(path string) bool {
-	basepath := filepath.Base(path)
-
-	if strings.Contains(basepath, ".well-known") ||
-		strings.Contains(basepath, ".htaccess") {
-		return false
-	}
-
-	if strings.HasPrefix(path, ".") {
-		return true
-	}
-
-	if strings.Contains(path, "node_modules") || strings.Contains(path, "__py_cache__") {
-		return true
-	}
-
-	return false
+// skipFile reports whether relpath must neither be uploaded nor deleted from
+// the remote.
+func skipFile(matcher *ignore.Matcher, relpath string, isDir bool) bool {
+	return matcher.Match(relpath, isDir)
 }
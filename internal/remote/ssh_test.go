@@ -1,6 +1,7 @@
 package remote_test
 
 import (
+	"context"
 	"fmt"
 	"os/exec"
 	"path/filepath"
@@ -20,6 +21,7 @@ func TestSync(t *testing.T) {
 	defer container.Nuke(t)
 
 	remotefs, err := remote.Connect(
+		context.Background(),
 		&remote.Config{Host: "localhost", Port: sshtest.Port, SSHConfig: sshtest.SSHKeyConfig()},
 	)
 	require.NoError(t, err)
@@ -70,7 +72,7 @@ func TestSync(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			err := remotefs.Sync(test.src, test.dest)
+			_, err := remotefs.Sync(test.src, test.dest, remote.SyncOptions{})
 			if !test.wantErr {
 				require.NoError(t, err)
 			}
@@ -0,0 +1,753 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alitto/pond"
+	"github.com/pkg/sftp"
+	"go.mlcdf.fr/owh/internal/ignore"
+	"go.mlcdf.fr/owh/internal/unit"
+	"go.mlcdf.fr/sally/logging"
+	"golang.org/x/xerrors"
+)
+
+// SyncOptions tunes the upload engine used by Sync.
+type SyncOptions struct {
+	// Concurrency is the number of files transferred in parallel.
+	Concurrency int
+	// RequestsPerFile is the number of concurrent SFTP requests used to
+	// transfer a single file.
+	RequestsPerFile int
+	// Ignore tells which paths are neither uploaded nor deleted from the
+	// remote. Defaults to ignore.Default().
+	Ignore *ignore.Matcher
+	// Preserve tells which remote paths are never deleted nor overwritten,
+	// such as user uploads or cache folders.
+	Preserve *ignore.Matcher
+	// PartsDir is the remote directory files are uploaded to before being
+	// moved in place, so an upload interrupted by a previous sync can be
+	// resumed. Defaults to dest. It must not be shared with other
+	// destinations, see PartsPath: the parts left once the sync is done are
+	// removed.
+	PartsDir string
+}
+
+const DefaultConcurrency = 8
+const DefaultRequestsPerFile = 64
+
+func (opts SyncOptions) withDefaults() SyncOptions {
+	if opts.Concurrency < 1 {
+		opts.Concurrency = DefaultConcurrency
+	}
+
+	if opts.RequestsPerFile < 1 {
+		opts.RequestsPerFile = DefaultRequestsPerFile
+	}
+
+	if opts.Ignore == nil {
+		opts.Ignore = ignore.Default()
+	}
+
+	return opts
+}
+
+// SyncStats summarizes what Sync did.
+type SyncStats struct {
+	Uploaded int64
+	Skipped  int64
+	Deleted  int64
+	Bytes    int64
+	// Plan is the list of changes Sync made.
+	Plan *Plan
+}
+
+func (stats *SyncStats) String() string {
+	return fmt.Sprintf(
+		"%d uploaded, %d skipped, %d deleted, %s transferred",
+		stats.Uploaded,
+		stats.Skipped,
+		stats.Deleted,
+		unit.FormatBytes(stats.Bytes),
+	)
+}
+
+// Sync mirrors src to dest: remote files missing locally are deleted and
+// only the new or modified files are uploaded, opts.Concurrency at a time.
+//
+// A manifest of the uploaded files is written to dest, so the next sync
+// can find the unchanged files without reading them back.
+func (c *Client) Sync(src string, dest string, opts SyncOptions) (*SyncStats, error) {
+	if src == "" {
+		return nil, ErrEmptyStringSrc
+	}
+
+	if dest == "" {
+		return nil, ErrEmptyStringDest
+	}
+
+	s, err := c.newSyncer(src, dest, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer s.close()
+
+	for _, dir := range []string{dest, s.partsDir} {
+		if err := s.client.MkdirAll(dir); err != nil {
+			return nil, xerrors.Errorf("error creating %s directory %w", dir, err)
+		}
+	}
+
+	plan, err := s.plan()
+	if err != nil {
+		return s.stats, err
+	}
+
+	s.stats.Plan = plan
+
+	if err := s.apply(plan); err != nil {
+		return s.stats, err
+	}
+
+	// every upload of the plan was moved in place, what's left in the parts
+	// directory of dest was interrupted by a previous sync and will never be
+	// resumed
+	removeStaleParts(s.client, s.partsDir)
+
+	return s.stats, writeManifest(s.client, dest, s.manifest)
+}
+
+// Plan returns the changes Sync would make to dest, without writing
+// anything. When dest is a symlink, the folder it points to is compared.
+func (c *Client) Plan(src string, dest string, opts SyncOptions) (*Plan, error) {
+	if src == "" {
+		return nil, ErrEmptyStringSrc
+	}
+
+	if dest == "" {
+		return nil, ErrEmptyStringDest
+	}
+
+	s, err := c.newSyncer(src, dest, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer s.close()
+
+	s.dest, err = resolveLink(s.client, dest)
+	if err != nil {
+		return nil, err
+	}
+	s.previous = readManifest(s.client, s.dest)
+
+	return s.plan()
+}
+
+// LocalFiles lists the files of src that Sync uploads, i.e. the ones not
+// ignored by matcher.
+func LocalFiles(src string, matcher *ignore.Matcher) ([]PlanFile, error) {
+	files := []PlanFile{}
+
+	err := walkLocal(src, matcher, func(relpath string, info os.FileInfo) error {
+		if !info.IsDir() {
+			files = append(files, PlanFile{Path: relpath, Size: info.Size()})
+		}
+		return nil
+	})
+
+	return files, err
+}
+
+// walkLocal calls fn for each path of src not ignored by matcher, in lexical
+// order. Ignored directories are not walked.
+func walkLocal(src string, matcher *ignore.Matcher, fn func(relpath string, info os.FileInfo) error) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if path == src {
+			return nil
+		}
+
+		if err != nil {
+			return xerrors.Errorf("error walking %s path %s: %w", src, path, err)
+		}
+
+		relpath, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return xerrors.Errorf("error while stat %s: %w", path, err)
+		}
+
+		if skipFile(matcher, relpath, info.IsDir()) {
+			logging.Debugf("Path %s skipped", path)
+
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		return fn(relpath, info)
+	})
+}
+
+// syncer holds the state shared by the steps of a Sync.
+type syncer struct {
+	conn            *Client
+	client          *sftp.Client
+	ownsClient      bool
+	pool            *pond.WorkerPool
+	src             string
+	dest            string
+	partsDir        string
+	requestsPerFile int
+	ignore          *ignore.Matcher
+	preserve        *ignore.Matcher
+	stats           *SyncStats
+
+	// generation counts the reconnections, so concurrent uploads losing the
+	// same connection only reconnect once.
+	connMu     sync.Mutex
+	generation int
+
+	// previous is the manifest written by the last sync, if it can be trusted.
+	previous *Manifest
+
+	mu        sync.Mutex
+	identical map[string]bool
+	manifest  *Manifest
+}
+
+func (c *Client) newSyncer(src string, dest string, opts SyncOptions) (*syncer, error) {
+	opts = opts.withDefaults()
+
+	client, owned, err := c.uploadClient(opts.RequestsPerFile)
+	if err != nil {
+		return nil, err
+	}
+
+	partsDir := opts.PartsDir
+	if partsDir == "" {
+		partsDir = dest
+	}
+
+	return &syncer{
+		conn:            c,
+		client:          client,
+		ownsClient:      owned,
+		pool:            pond.New(opts.Concurrency, 0),
+		src:             src,
+		dest:            dest,
+		partsDir:        partsDir,
+		requestsPerFile: opts.RequestsPerFile,
+		ignore:          opts.Ignore,
+		preserve:        opts.Preserve,
+		stats:           &SyncStats{},
+		previous:        readManifest(client, dest),
+		identical:       map[string]bool{},
+		manifest:        NewManifest(),
+	}, nil
+}
+
+// uploadClient returns the sftp session of the client, or a dedicated one
+// when requestsPerFile differs from the default. owned tells whether the
+// session has to be closed once done.
+func (c *Client) uploadClient(requestsPerFile int) (client *sftp.Client, owned bool, err error) {
+	if requestsPerFile == DefaultRequestsPerFile {
+		client, err = c.session()
+		return client, false, err
+	}
+
+	client, err = sftp.NewClient(
+		c.conn,
+		sftp.MaxConcurrentRequestsPerFile(requestsPerFile),
+		sftp.UseConcurrentWrites(true),
+	)
+	if err != nil {
+		return nil, false, xerrors.Errorf("error opening sftp session: %w", err)
+	}
+
+	return client, true, nil
+}
+
+func (s *syncer) close() {
+	s.pool.StopAndWait()
+
+	if s.ownsClient {
+		s.client.Close()
+	}
+}
+
+// session returns the sftp client to use and the generation of its
+// connection.
+func (s *syncer) session() (*sftp.Client, int) {
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+
+	return s.client, s.generation
+}
+
+// reconnect dials the hosting again, unless another upload already did
+// since the connection of generation gen was lost.
+func (s *syncer) reconnect(gen int) error {
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+
+	if gen != s.generation {
+		return nil
+	}
+
+	if s.ownsClient {
+		s.client.Close()
+	}
+
+	if err := s.conn.Reconnect(context.Background()); err != nil {
+		return err
+	}
+
+	client, owned, err := s.conn.uploadClient(s.requestsPerFile)
+	if err != nil {
+		return err
+	}
+
+	s.client = client
+	s.ownsClient = owned
+	s.generation++
+
+	return nil
+}
+
+// candidate is a remote file of the same size as its local counterpart,
+// whose content has to be compared.
+type candidate struct {
+	relpath    string
+	localpath  string
+	remotepath string
+	remotefile os.FileInfo
+}
+
+// plan walks dest then src to find what has to change on dest.
+func (s *syncer) plan() (*Plan, error) {
+	plan := newPlan()
+
+	// remote directories and files whose type matches the local one
+	remoteDirs := map[string]bool{}
+	remoteFiles := map[string]bool{}
+
+	candidates := []candidate{}
+
+	walker := s.client.Walk(s.dest)
+	for walker.Step() {
+		remotepath := walker.Path()
+
+		if err := walker.Err(); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, xerrors.Errorf("error while stat remote file %s: %w", remotepath, err)
+		}
+
+		if remotepath == s.dest {
+			continue
+		}
+
+		relpath, err := filepath.Rel(s.dest, remotepath)
+		if err != nil {
+			return nil, err
+		}
+
+		if relpath == ManifestName || strings.HasSuffix(relpath, partSuffix) {
+			continue
+		}
+
+		localpath := filepath.Join(s.src, relpath)
+		remotefile := walker.Stat()
+
+		// Ignored and preserved paths are left untouched on the remote
+		if skipFile(s.ignore, relpath, remotefile.IsDir()) || s.preserve.Match(relpath, remotefile.IsDir()) {
+			if remotefile.IsDir() {
+				walker.SkipDir()
+			}
+			continue
+		}
+
+		localfile, err := os.Stat(localpath)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				return nil, xerrors.Errorf("error while stat %s: %w", localpath, err)
+			}
+
+			// The file is present on remote but not locally
+			if remotefile.IsDir() {
+				plan.Rmdir = append(plan.Rmdir, relpath)
+			} else {
+				plan.Delete = append(plan.Delete, PlanFile{Path: relpath, Size: remotefile.Size()})
+			}
+			continue
+		}
+
+		// Both are directories
+		if localfile.IsDir() && remotefile.IsDir() {
+			remoteDirs[relpath] = true
+			continue
+		}
+
+		// Both are files: the remote one is overwritten unless identical
+		if !localfile.IsDir() && !remotefile.IsDir() {
+			remoteFiles[relpath] = true
+
+			if localfile.Size() == remotefile.Size() {
+				candidates = append(candidates, candidate{
+					relpath:    relpath,
+					localpath:  localpath,
+					remotepath: remotepath,
+					remotefile: remotefile,
+				})
+			}
+			continue
+		}
+
+		// One is a dir and the other is a file
+		logging.Debugf("%s changed type", remotepath)
+
+		if remotefile.IsDir() {
+			walker.SkipDir()
+			plan.Rmdir = append(plan.Rmdir, relpath)
+		} else {
+			plan.Delete = append(plan.Delete, PlanFile{Path: relpath, Size: remotefile.Size()})
+		}
+	}
+
+	if err := s.compare(candidates); err != nil {
+		return nil, err
+	}
+
+	err := walkLocal(s.src, s.ignore, func(relpath string, localfile os.FileInfo) error {
+		if s.preserve.Match(relpath, localfile.IsDir()) {
+			plan.Shadowed = append(plan.Shadowed, relpath)
+
+			if localfile.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		switch {
+		case localfile.IsDir():
+			if !remoteDirs[relpath] {
+				plan.Mkdir = append(plan.Mkdir, relpath)
+			}
+		case s.identical[relpath]:
+			plan.Unchanged++
+		case remoteFiles[relpath]:
+			plan.Modify = append(plan.Modify, PlanFile{Path: relpath, Size: localfile.Size()})
+		default:
+			plan.Add = append(plan.Add, PlanFile{Path: relpath, Size: localfile.Size()})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	plan.sort()
+
+	return plan, nil
+}
+
+// compare finds the candidates identical to their local counterpart. The
+// manifest of the previous sync is trusted first, the remaining files are
+// hashed on the hosting with sha256sum, and as a last resort downloaded.
+func (s *syncer) compare(candidates []candidate) error {
+	unknown := []candidate{}
+
+	group, _ := s.pool.GroupContext(context.Background())
+
+	for _, cand := range candidates {
+		cand := cand
+
+		entry, ok := s.previous.Lookup(cand.relpath, cand.remotefile)
+		if !ok {
+			unknown = append(unknown, cand)
+			continue
+		}
+
+		group.Submit(func() error {
+			return s.compareHash(cand, entry.SHA256)
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return err
+	}
+
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	paths := make([]string, 0, len(unknown))
+	for _, cand := range unknown {
+		paths = append(paths, cand.remotepath)
+	}
+
+	hashes, err := s.conn.RemoteHashes(paths)
+	if err != nil {
+		logging.Debugf("falling back to downloading files to compare them: %s", err)
+		hashes = map[string]string{}
+	}
+
+	group, _ = s.pool.GroupContext(context.Background())
+
+	for _, cand := range unknown {
+		cand := cand
+
+		if hash, ok := hashes[cand.remotepath]; ok {
+			group.Submit(func() error {
+				return s.compareHash(cand, hash)
+			})
+			continue
+		}
+
+		group.Submit(func() error {
+			same, err := isIdentical(s.client, cand.localpath, cand.remotepath)
+			if err != nil || !same {
+				return err
+			}
+
+			hash, err := localHash(cand.localpath)
+			if err != nil {
+				return err
+			}
+
+			s.markIdentical(cand, hash)
+			return nil
+		})
+	}
+
+	return group.Wait()
+}
+
+func (s *syncer) compareHash(cand candidate, remoteHash string) error {
+	hash, err := localHash(cand.localpath)
+	if err != nil {
+		return err
+	}
+
+	if hash == remoteHash {
+		s.markIdentical(cand, hash)
+	}
+
+	return nil
+}
+
+func (s *syncer) markIdentical(cand candidate, hash string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.identical[cand.relpath] = true
+	s.manifest.Files[cand.relpath] = ManifestEntry{
+		Size:    cand.remotefile.Size(),
+		ModTime: cand.remotefile.ModTime().Unix(),
+		SHA256:  hash,
+	}
+}
+
+// apply removes, creates and uploads what the plan lists. The uploads are
+// submitted to the pool.
+func (s *syncer) apply(plan *Plan) error {
+	for _, relpath := range plan.removals() {
+		if err := forceRemove(s.client, filepath.Join(s.dest, relpath)); err != nil {
+			return err
+		}
+
+		atomic.AddInt64(&s.stats.Deleted, 1)
+	}
+
+	for _, relpath := range plan.Mkdir {
+		remotepath := filepath.Join(s.dest, relpath)
+
+		if err := s.client.MkdirAll(remotepath); err != nil {
+			return xerrors.Errorf("error while mkdir %s on remote: %w", remotepath, err)
+		}
+	}
+
+	atomic.AddInt64(&s.stats.Skipped, int64(plan.Unchanged))
+
+	pending := []*upload{}
+	for _, file := range append(plan.Add, plan.Modify...) {
+		pending = append(pending, &upload{
+			relpath:    file.Path,
+			localpath:  filepath.Join(s.src, file.Path),
+			remotepath: filepath.Join(s.dest, file.Path),
+		})
+	}
+
+	// Files whose uploaded content doesn't match are sent again once
+	for round := 0; len(pending) > 0; round++ {
+		if round == 2 {
+			return xerrors.Errorf("%w for %s after upload", ErrChecksumMismatch, pending[0].relpath)
+		}
+
+		group, _ := s.pool.GroupContext(context.Background())
+
+		for _, u := range pending {
+			u := u
+			group.Submit(func() error {
+				return s.uploadFile(u)
+			})
+		}
+
+		if err := group.Wait(); err != nil {
+			return err
+		}
+
+		corrupted, err := s.verify(pending)
+		if err != nil {
+			return err
+		}
+
+		pending = corrupted
+	}
+
+	return nil
+}
+
+// upload is a file of the plan to upload.
+type upload struct {
+	relpath    string
+	localpath  string
+	remotepath string
+	localfile  os.FileInfo
+	hash       string
+	// part is the temporary location of the file until it's verified
+	part string
+}
+
+// uploadFile sends u to its temporary location, reconnecting and resuming
+// where it stopped when the connection drops.
+func (s *syncer) uploadFile(u *upload) error {
+	if u.hash == "" {
+		localfile, err := os.Stat(u.localpath)
+		if err != nil {
+			return xerrors.Errorf("error while stat %s: %w", u.localpath, err)
+		}
+
+		hash, err := localHash(u.localpath)
+		if err != nil {
+			return err
+		}
+
+		u.localfile = localfile
+		u.hash = hash
+		u.part = filepath.Join(s.partsDir, partName(u.relpath, hash))
+	}
+
+	for attempt := 1; ; attempt++ {
+		client, gen := s.session()
+
+		written, err := resumeUpload(client, u.localpath, u.part)
+		atomic.AddInt64(&s.stats.Bytes, written)
+
+		if err == nil {
+			return nil
+		}
+
+		if attempt == maxUploadAttempts || !isConnectionError(err) {
+			return err
+		}
+
+		logging.Debugf("upload of %s interrupted, resuming: %s", u.relpath, err)
+		time.Sleep(time.Duration(attempt) * time.Second)
+
+		if err := s.reconnect(gen); err != nil {
+			return err
+		}
+	}
+}
+
+// verify compares the checksum of the uploaded files with the local ones.
+// The matching files are moved in place, the others are removed and
+// returned to be uploaded again.
+func (s *syncer) verify(uploads []*upload) ([]*upload, error) {
+	client, _ := s.session()
+
+	paths := make([]string, 0, len(uploads))
+	for _, u := range uploads {
+		paths = append(paths, u.part)
+	}
+
+	hashes, err := s.conn.RemoteHashes(paths)
+	if err != nil {
+		logging.Debugf("falling back to downloading files to verify them: %s", err)
+		hashes = map[string]string{}
+	}
+
+	corrupted := []*upload{}
+
+	group, _ := s.pool.GroupContext(context.Background())
+
+	for _, u := range uploads {
+		u := u
+
+		hash, ok := hashes[u.part]
+		if !ok {
+			hash, err = remoteHash(client, u.part)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if hash != u.hash {
+			logging.Debugf("%s: %s, uploading it again", u.relpath, ErrChecksumMismatch)
+
+			if err := client.Remove(u.part); err != nil {
+				return nil, xerrors.Errorf("error removing %s: %w", u.part, err)
+			}
+
+			corrupted = append(corrupted, u)
+			continue
+		}
+
+		group.Submit(func() error {
+			return s.finishUpload(client, u)
+		})
+	}
+
+	return corrupted, group.Wait()
+}
+
+// finishUpload moves the verified upload u in place.
+func (s *syncer) finishUpload(client *sftp.Client, u *upload) error {
+	if err := client.PosixRename(u.part, u.remotepath); err != nil {
+		return xerrors.Errorf("error moving %s to %s: %w", u.part, u.remotepath, err)
+	}
+
+	// The remote mtime is the one recorded in the manifest, it tells
+	// whether the file was modified on the hosting since the upload.
+	if err := client.Chtimes(u.remotepath, time.Now(), u.localfile.ModTime()); err != nil {
+		return xerrors.Errorf("error setting mtime of %s: %w", u.remotepath, err)
+	}
+
+	s.mu.Lock()
+	s.manifest.Files[u.relpath] = ManifestEntry{
+		Size:    u.localfile.Size(),
+		ModTime: u.localfile.ModTime().Unix(),
+		SHA256:  u.hash,
+	}
+	s.mu.Unlock()
+
+	atomic.AddInt64(&s.stats.Uploaded, 1)
+
+	return nil
+}
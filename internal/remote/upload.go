@@ -0,0 +1,161 @@
+package remote
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/sftp"
+	"go.mlcdf.fr/sally/logging"
+	"golang.org/x/xerrors"
+)
+
+// PartsDir is the directory, relative to the hosting home, holding the files
+// being uploaded by a deployment.
+const PartsDir = ".owh/parts"
+
+// PartsPath returns the directory holding the files being uploaded to dest.
+// Each destination has its own, so that the stale parts removed once a sync
+// is done are never the ones of another website.
+func PartsPath(dest string) string {
+	return filepath.Join(PartsDir, dest)
+}
+
+// uploadChunkSize is the amount of data fully written before the next chunk
+// is sent. An interrupted upload resumes from the last complete chunk.
+const uploadChunkSize = 8 << 20
+
+// maxUploadAttempts is the number of times an upload is resumed after the
+// connection dropped.
+const maxUploadAttempts = 5
+
+// partSuffix is the extension of files being uploaded.
+const partSuffix = ".owh-part"
+
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// partName returns the temporary name under which relpath is uploaded. It
+// depends on the content of the file so an upload interrupted by a previous
+// sync is only resumed if the file didn't change since.
+func partName(relpath string, hash string) string {
+	sum := sha256.Sum256([]byte(relpath + "\x00" + hash))
+	return "." + hex.EncodeToString(sum[:16]) + partSuffix
+}
+
+// removeStaleParts removes the uploads left in dir. Failures are only logged
+// since they don't affect the deployment.
+func removeStaleParts(client *sftp.Client, dir string) {
+	entries, err := client.ReadDir(dir)
+	if err != nil {
+		logging.Debugf("error listing %s: %s", dir, err)
+		return
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), partSuffix) {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		if err := client.Remove(path); err != nil {
+			logging.Debugf("error removing %s: %s", path, err)
+		}
+	}
+}
+
+// resumeUpload copies localpath to remotepath chunk by chunk, starting from
+// the chunks a previous attempt already wrote. It returns the number of bytes
+// sent.
+func resumeUpload(client *sftp.Client, localpath string, remotepath string) (int64, error) {
+	localf, err := os.Open(localpath)
+	if err != nil {
+		return 0, xerrors.Errorf("error opening %s: %w", localpath, err)
+	}
+	defer localf.Close()
+
+	localfile, err := localf.Stat()
+	if err != nil {
+		return 0, xerrors.Errorf("error while stat %s: %w", localpath, err)
+	}
+
+	remotef, err := client.OpenFile(remotepath, os.O_WRONLY|os.O_CREATE)
+	if err != nil {
+		return 0, xerrors.Errorf("error creating %s: %w", remotepath, err)
+	}
+	defer remotef.Close()
+
+	remotefile, err := remotef.Stat()
+	if err != nil {
+		return 0, xerrors.Errorf("error while stat %s: %w", remotepath, err)
+	}
+
+	// Chunks are written concurrently, only the ones before the last
+	// chunk boundary are known to be complete.
+	offset := remotefile.Size() - remotefile.Size()%uploadChunkSize
+	if offset > localfile.Size() {
+		offset = 0
+	}
+
+	if _, err := localf.Seek(offset, io.SeekStart); err != nil {
+		return 0, xerrors.Errorf("error seeking %s: %w", localpath, err)
+	}
+
+	if _, err := remotef.Seek(offset, io.SeekStart); err != nil {
+		return 0, xerrors.Errorf("error seeking %s: %w", remotepath, err)
+	}
+
+	var written int64
+
+	for {
+		// *sftp.File implements io.ReaderFrom, which pipelines the writes
+		n, err := remotef.ReadFrom(io.LimitReader(localf, uploadChunkSize))
+		written += n
+		if err != nil {
+			return written, xerrors.Errorf("error copying %s to %s: %w", localpath, remotepath, err)
+		}
+
+		if n < uploadChunkSize {
+			break
+		}
+	}
+
+	if err := remotef.Truncate(offset + written); err != nil {
+		return written, xerrors.Errorf("error truncating %s: %w", remotepath, err)
+	}
+
+	return written, nil
+}
+
+// remoteHash computes the SHA-256 of path by reading it back.
+func remoteHash(client *sftp.Client, path string) (string, error) {
+	f, err := client.Open(path)
+	if err != nil {
+		return "", xerrors.Errorf("error opening file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", xerrors.Errorf("error hashing %s: %w", path, err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// isConnectionError reports whether err is caused by the SSH connection
+// being lost, in which case reconnecting may help.
+func isConnectionError(err error) bool {
+	var netErr net.Error
+
+	return errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, sftp.ErrSSHFxConnectionLost) ||
+		errors.Is(err, sftp.ErrSSHFxNoConnection) ||
+		errors.As(err, &netErr)
+}
@@ -0,0 +1,106 @@
+package remote
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/require"
+)
+
+// newPipeClient returns an sftp client talking to an in-process server
+// serving the local file system.
+func newPipeClient(t *testing.T) *sftp.Client {
+	t.Helper()
+
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+
+	server, err := sftp.NewServer(struct {
+		io.Reader
+		io.WriteCloser
+	}{serverReader, serverWriter})
+	require.NoError(t, err)
+
+	go server.Serve() //nolint:errcheck
+
+	client, err := sftp.NewClientPipe(clientReader, clientWriter, sftp.UseConcurrentWrites(true))
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		// closing the server side first lets the client stop reading
+		server.Close()
+		client.Close()
+	})
+
+	return client
+}
+
+func TestResumeUpload(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	client := newPipeClient(t)
+
+	content := make([]byte, uploadChunkSize+1234)
+	_, err := rand.Read(content)
+	require.NoError(t, err)
+
+	localpath := filepath.Join(dir, "video.mp4")
+	require.NoError(t, os.WriteFile(localpath, content, 0o600))
+
+	// an interrupted upload: the first chunk is complete, the second one
+	// only partly written
+	partial := append([]byte{}, content[:uploadChunkSize]...)
+	partial = append(partial, bytes.Repeat([]byte{0}, 100)...)
+
+	remotepath := filepath.Join(dir, partName("video.mp4", "abc"))
+	require.NoError(t, os.WriteFile(remotepath, partial, 0o600))
+
+	written, err := resumeUpload(client, localpath, remotepath)
+	require.NoError(t, err)
+	require.Equal(t, int64(1234), written, "only the incomplete chunk should be sent again")
+
+	got, err := os.ReadFile(remotepath)
+	require.NoError(t, err)
+	require.True(t, bytes.Equal(content, got))
+
+	hash, err := localHash(localpath)
+	require.NoError(t, err)
+
+	remote, err := remoteHash(client, remotepath)
+	require.NoError(t, err)
+	require.Equal(t, hash, remote)
+}
+
+func TestPartName(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, partName("a.html", "abc"), partName("a.html", "abc"))
+	require.NotEqual(t, partName("a.html", "abc"), partName("b.html", "abc"))
+	require.NotEqual(t, partName("a.html", "abc"), partName("a.html", "def"))
+}
+
+func TestRemoveStaleParts(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	client := newPipeClient(t)
+
+	stale := filepath.Join(dir, partName("index.html", "abc"))
+	require.NoError(t, os.WriteFile(stale, []byte("<html>"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.html"), []byte("<html>"), 0o600))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "assets"+partSuffix), 0o700))
+
+	removeStaleParts(client, dir)
+
+	_, err := os.Stat(stale)
+	require.True(t, os.IsNotExist(err))
+
+	require.FileExists(t, filepath.Join(dir, "index.html"))
+	require.DirExists(t, filepath.Join(dir, "assets"+partSuffix))
+}
@@ -70,3 +70,19 @@ func (b Data) Megabits() float64 {
 func (b Data) Gigabits() float64 {
 	return float64(b / Gigabit)
 }
+
+// FormatBytes formats a number of bytes using the largest fitting SI unit.
+func FormatBytes(n int64) string {
+	size := Data(n)
+
+	switch {
+	case size >= Gigabit:
+		return fmt.Sprintf("%.2f GB", size.Gigabits())
+	case size >= Megabit:
+		return fmt.Sprintf("%.2f MB", size.Megabits())
+	case size >= Kilobit:
+		return fmt.Sprintf("%.2f KB", size.Kilobits())
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
@@ -2,6 +2,7 @@ package view
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
 	"io"
 	"strings"
@@ -54,6 +55,14 @@ func (view *View) PrintErr(err error) int {
 	return 1
 }
 
+// JSON renders v as indented JSON.
+func (view *View) JSON(v any) error {
+	encoder := json.NewEncoder(view.Writer)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(v)
+}
+
 // Table renders the table defined by the given properties into w. Both title &
 // cols are optional.
 func (view *View) Table(title string, rows [][]string, cols ...string) error {
@@ -132,3 +141,12 @@ func Highlight(str string) string {
 func Special(str string) string {
 	return lipgloss.NewStyle().Foreground(StyleSpecial).Render(str)
 }
+
+// Mask hides a secret value, unless reveal is true.
+func Mask(value string, reveal bool) string {
+	if reveal || value == "" {
+		return value
+	}
+
+	return "********"
+}
@@ -0,0 +1,301 @@
+// Package watch reports the paths changed in a directory.
+package watch
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.mlcdf.fr/owh/internal/ignore"
+	"go.mlcdf.fr/sally/logging"
+	"golang.org/x/xerrors"
+)
+
+// DefaultDebounce is the time without changes waited for before reporting
+// them.
+const DefaultDebounce = 300 * time.Millisecond
+
+// DefaultMaxWait is the longest time the changes are held back for, when the
+// directory keeps changing faster than the debounce duration.
+const DefaultMaxWait = 5 * time.Second
+
+// PollInterval is the interval at which the directory is walked when it
+// can't be watched with the notifications of the system.
+const PollInterval = time.Second
+
+// Watcher reports the changes made in a directory, by batches.
+type Watcher struct {
+	dir      string
+	matcher  *ignore.Matcher
+	debounce time.Duration
+	maxWait  time.Duration
+	// poll forces the directory to be walked every pollInterval instead of
+	// relying on the notifications of the system.
+	poll         bool
+	pollInterval time.Duration
+}
+
+// New returns a Watcher of dir. The paths matched by matcher are left out.
+func New(dir string, matcher *ignore.Matcher, debounce time.Duration) *Watcher {
+	if debounce <= 0 {
+		debounce = DefaultDebounce
+	}
+
+	return &Watcher{dir: dir, matcher: matcher, debounce: debounce, maxWait: DefaultMaxWait, pollInterval: PollInterval}
+}
+
+// Run calls fn with the paths, relative to the directory, created, modified
+// or removed since the previous call, once no change happened for the
+// debounce duration, or at the latest DefaultMaxWait after the first of
+// them. It returns when ctx is done or fn fails.
+func (w *Watcher) Run(ctx context.Context, fn func(paths []string) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	events := make(chan string)
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- w.watch(ctx, events)
+	}()
+
+	pending := map[string]bool{}
+	var fire, deadline <-chan time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errs:
+			return err
+		case path := <-events:
+			if len(pending) == 0 {
+				deadline = time.After(w.maxWait)
+			}
+
+			pending[path] = true
+			fire = time.After(w.debounce)
+			continue
+		case <-fire:
+		case <-deadline:
+		}
+
+		paths := make([]string, 0, len(pending))
+		for path := range pending {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		pending = map[string]bool{}
+		fire = nil
+		deadline = nil
+
+		if err := fn(paths); err != nil {
+			return err
+		}
+	}
+}
+
+// watch sends the changed paths to events until ctx is done. The
+// notifications of the system are used when available, else the directory
+// is polled.
+func (w *Watcher) watch(ctx context.Context, events chan<- string) error {
+	if !w.poll {
+		err := w.notify(ctx, events)
+		if err == nil || ctx.Err() != nil {
+			return nil
+		}
+
+		logging.Debugf("falling back to polling %s: %s", w.dir, err)
+	}
+
+	return w.walkEvery(ctx, w.pollInterval, events)
+}
+
+func (w *Watcher) notify(ctx context.Context, events chan<- string) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	if err := w.addDirs(watcher, w.dir); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-watcher.Errors:
+			return err
+		case event := <-watcher.Events:
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+
+			relpath, err := filepath.Rel(w.dir, event.Name)
+			if err != nil {
+				return err
+			}
+
+			info, err := os.Stat(event.Name)
+			isDir := err == nil && info.IsDir()
+
+			if w.matcher.Match(relpath, isDir) {
+				continue
+			}
+
+			// the content of a new directory is watched too
+			if isDir && event.Has(fsnotify.Create) {
+				if err := w.addDirs(watcher, event.Name); err != nil {
+					logging.Debugf("failed to watch %s: %s", event.Name, err)
+				}
+			}
+
+			select {
+			case events <- relpath:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+}
+
+// addDirs watches dir and its subdirectories which aren't ignored.
+func (w *Watcher) addDirs(watcher *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return xerrors.Errorf("error walking %s: %w", path, err)
+		}
+
+		if !d.IsDir() {
+			return nil
+		}
+
+		relpath, err := filepath.Rel(w.dir, path)
+		if err != nil {
+			return err
+		}
+
+		if w.matcher.Match(relpath, true) {
+			return filepath.SkipDir
+		}
+
+		if err := watcher.Add(path); err != nil {
+			return xerrors.Errorf("error watching %s: %w", path, err)
+		}
+
+		return nil
+	})
+}
+
+// entry is the state of a path, compared between two walks.
+type entry struct {
+	size    int64
+	modTime time.Time
+	isDir   bool
+}
+
+// walkEvery walks the directory every interval and sends the paths which
+// changed since the previous walk.
+func (w *Watcher) walkEvery(ctx context.Context, interval time.Duration, events chan<- string) error {
+	previous, err := w.snapshot()
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		current, err := w.snapshot()
+		if err != nil {
+			return err
+		}
+
+		for _, path := range changes(previous, current) {
+			select {
+			case events <- path:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+
+		previous = current
+	}
+}
+
+func (w *Watcher) snapshot() (map[string]entry, error) {
+	entries := map[string]entry{}
+
+	err := filepath.WalkDir(w.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// removed while walking
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return xerrors.Errorf("error walking %s: %w", path, err)
+		}
+
+		if path == w.dir {
+			return nil
+		}
+
+		relpath, err := filepath.Rel(w.dir, path)
+		if err != nil {
+			return err
+		}
+
+		if w.matcher.Match(relpath, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+
+		entries[relpath] = entry{size: info.Size(), modTime: info.ModTime(), isDir: d.IsDir()}
+		return nil
+	})
+
+	return entries, err
+}
+
+// changes returns the paths created, modified or removed between two
+// snapshots, in lexical order.
+func changes(previous map[string]entry, current map[string]entry) []string {
+	paths := []string{}
+
+	for path, e := range current {
+		if old, ok := previous[path]; !ok || old.isDir != e.isDir || (!e.isDir && (old.size != e.size || !old.modTime.Equal(e.modTime))) {
+			paths = append(paths, path)
+		}
+	}
+
+	for path := range previous {
+		if _, ok := current[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+
+	sort.Strings(paths)
+	return paths
+}
@@ -0,0 +1,136 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.mlcdf.fr/owh/internal/ignore"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	for _, poll := range []bool{false, true} {
+		poll := poll
+
+		t.Run(map[bool]string{false: "notify", true: "poll"}[poll], func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0755))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "old.html"), []byte("old"), 0644))
+
+			w := New(dir, ignore.Default(), 50*time.Millisecond)
+			w.poll = poll
+			w.pollInterval = 20 * time.Millisecond
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			batches := make(chan []string, 10)
+			go func() {
+				_ = w.Run(ctx, func(paths []string) error {
+					batches <- paths
+					return nil
+				})
+			}()
+
+			// let the watcher start
+			time.Sleep(200 * time.Millisecond)
+
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "index.html"), []byte("index"), 0644))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, ".git", "HEAD"), []byte("ref"), 0644))
+			require.NoError(t, os.Remove(filepath.Join(dir, "old.html")))
+
+			got := map[string]bool{}
+			for !got["index.html"] || !got["old.html"] {
+				select {
+				case paths := <-batches:
+					for _, path := range paths {
+						got[path] = true
+					}
+				case <-ctx.Done():
+					t.Fatalf("missing changes, got %v", got)
+				}
+			}
+
+			require.False(t, got[".git/HEAD"])
+			require.False(t, got[".git"])
+		})
+	}
+}
+
+func TestRunMaxWait(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	w := New(dir, ignore.Default(), 200*time.Millisecond)
+	w.maxWait = 300 * time.Millisecond
+	w.poll = true
+	w.pollInterval = 20 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batches := make(chan []string, 10)
+	go func() {
+		_ = w.Run(ctx, func(paths []string) error {
+			batches <- paths
+			return nil
+		})
+	}()
+
+	// let the watcher start
+	time.Sleep(100 * time.Millisecond)
+
+	// changes keep coming faster than the debounce duration
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		content := []byte{}
+		for i := 0; i < 150 && ctx.Err() == nil; i++ {
+			content = append(content, 'a')
+			_ = os.WriteFile(filepath.Join(dir, "index.html"), content, 0644)
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+
+	select {
+	case paths := <-batches:
+		require.Equal(t, []string{"index.html"}, paths)
+	case <-done:
+		t.Fatal("no changes reported while the directory kept changing")
+	}
+
+	<-done
+}
+
+func TestChanges(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	previous := map[string]entry{
+		"same.html":     {size: 1, modTime: now},
+		"modified.html": {size: 1, modTime: now},
+		"touched.html":  {size: 1, modTime: now},
+		"removed.html":  {size: 1, modTime: now},
+		"blog":          {isDir: true, modTime: now},
+	}
+
+	current := map[string]entry{
+		"same.html":     {size: 1, modTime: now},
+		"modified.html": {size: 2, modTime: now},
+		"touched.html":  {size: 1, modTime: now.Add(time.Second)},
+		"added.html":    {size: 1, modTime: now},
+		"blog":          {isDir: true, modTime: now.Add(time.Second)},
+	}
+
+	require.Equal(t, []string{"added.html", "modified.html", "removed.html", "touched.html"}, changes(previous, current))
+}
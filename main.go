@@ -100,9 +100,69 @@ func main() {
 		HelpWriter:   os.Stdout,
 		HelpFunc:     HelpFunc("owh", "Deploy websites to OVHcloud Web Hosting."),
 		Commands: map[string]cli.CommandFactory{
+			"config": func() (cli.Command, error) {
+				return &command.ConfigCommand{App: *app}, nil
+			},
+			"config migrate-secrets": func() (cli.Command, error) {
+				return &command.ConfigMigrateSecretsCommand{App: *app}, nil
+			},
+			"cron": func() (cli.Command, error) {
+				return &command.CronCommand{App: *app}, nil
+			},
+			"cron add": func() (cli.Command, error) {
+				return &command.CronAddCommand{App: *app}, nil
+			},
+			"cron disable": func() (cli.Command, error) {
+				return &command.CronDisableCommand{App: *app}, nil
+			},
+			"cron enable": func() (cli.Command, error) {
+				return &command.CronEnableCommand{App: *app}, nil
+			},
+			"cron list": func() (cli.Command, error) {
+				return &command.CronListCommand{App: *app}, nil
+			},
+			"cron remove": func() (cli.Command, error) {
+				return &command.CronRemoveCommand{App: *app}, nil
+			},
+			"cron update": func() (cli.Command, error) {
+				return &command.CronUpdateCommand{App: *app}, nil
+			},
+			"databases": func() (cli.Command, error) {
+				return &command.DatabasesCommand{App: *app}, nil
+			},
+			"databases changepass": func() (cli.Command, error) {
+				return &command.DatabasesChangePassCommand{App: *app}, nil
+			},
+			"databases create": func() (cli.Command, error) {
+				return &command.DatabasesCreateCommand{App: *app}, nil
+			},
+			"databases delete": func() (cli.Command, error) {
+				return &command.DatabasesDeleteCommand{App: *app}, nil
+			},
+			"databases download": func() (cli.Command, error) {
+				return &command.DatabasesDownloadCommand{App: *app}, nil
+			},
+			"databases dump": func() (cli.Command, error) {
+				return &command.DatabasesDumpCommand{App: *app}, nil
+			},
+			"databases dumps": func() (cli.Command, error) {
+				return &command.DatabasesDumpsCommand{App: *app}, nil
+			},
+			"databases info": func() (cli.Command, error) {
+				return &command.DatabasesInfoCommand{App: *app}, nil
+			},
+			"databases restore": func() (cli.Command, error) {
+				return &command.DatabasesRestoreCommand{App: *app}, nil
+			},
 			"deploy": func() (cli.Command, error) {
 				return &command.DeployCommand{App: *app}, nil
 			},
+			"deploys": func() (cli.Command, error) {
+				return &command.DeploysCommand{App: *app}, nil
+			},
+			"diff": func() (cli.Command, error) {
+				return &command.DiffCommand{App: *app}, nil
+			},
 			"domains": func() (cli.Command, error) {
 				return &command.DomainsCommand{App: *app}, nil
 			},
@@ -112,6 +172,24 @@ func main() {
 			"domains detach": func() (cli.Command, error) {
 				return &command.DetachCommand{App: *app}, nil
 			},
+			"env": func() (cli.Command, error) {
+				return &command.EnvCommand{App: *app}, nil
+			},
+			"env import": func() (cli.Command, error) {
+				return &command.EnvImportCommand{App: *app}, nil
+			},
+			"env list": func() (cli.Command, error) {
+				return &command.EnvListCommand{App: *app}, nil
+			},
+			"env set": func() (cli.Command, error) {
+				return &command.EnvSetCommand{App: *app}, nil
+			},
+			"env unset": func() (cli.Command, error) {
+				return &command.EnvUnsetCommand{App: *app}, nil
+			},
+			"exec": func() (cli.Command, error) {
+				return &command.ExecCommand{App: *app}, nil
+			},
 			"hostings": func() (cli.Command, error) {
 				return &command.HostingsCommand{App: *app}, nil
 			},
@@ -135,9 +213,27 @@ func main() {
 			"open": func() (cli.Command, error) {
 				return &command.OpenCommand{App: *app}, nil
 			},
+			"pull": func() (cli.Command, error) {
+				return &command.PullCommand{App: *app}, nil
+			},
+			"releases": func() (cli.Command, error) {
+				return &command.ReleasesCommand{App: *app}, nil
+			},
 			"remove": func() (cli.Command, error) {
 				return &command.RemoveCommand{App: *app}, nil
 			},
+			"rollback": func() (cli.Command, error) {
+				return &command.RollbackCommand{App: *app}, nil
+			},
+			"runtime": func() (cli.Command, error) {
+				return &command.RuntimeCommand{App: *app}, nil
+			},
+			"runtime set": func() (cli.Command, error) {
+				return &command.RuntimeSetCommand{App: *app}, nil
+			},
+			"runtime versions": func() (cli.Command, error) {
+				return &command.RuntimeVersionsCommand{App: *app}, nil
+			},
 			"tasks": func() (cli.Command, error) {
 				return &command.TasksCommand{App: *app}, nil
 			},
@@ -156,6 +252,9 @@ func main() {
 			"users": func() (cli.Command, error) {
 				return &command.UsersCommand{App: *app}, nil
 			},
+			"users add-key": func() (cli.Command, error) {
+				return &command.UsersAddKeyCommand{App: *app}, nil
+			},
 			"users changepass": func() (cli.Command, error) {
 				return &command.UsersChangePassCommand{App: *app}, nil
 			},